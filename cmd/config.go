@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/liwanggui/dnscli-go/config"
+	"github.com/liwanggui/dnscli-go/dnsapi"
 	"github.com/spf13/cobra"
 	"strings"
 )
@@ -17,15 +18,15 @@ var (
 	}
 
 	cAddCmd = &cobra.Command{
-		Use:   "add",
-		Short: "添加 DNS 服务商配置",
-		Long:  `添加 DNS 服务商配置`,
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:          "add",
+		Short:        "添加 DNS 服务商配置",
+		Long:         `添加 DNS 服务商配置`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			isDefault, _ := cmd.Flags().GetBool("default")
-			err := config.AddConfig(configName, providerType, secretID, secretKey, apiToken, apiEmail, apiKey, isDefault)
-			if err != nil {
-				cobra.CheckErr(err)
-			}
+			endpoint, _ := cmd.Flags().GetString("endpoint")
+			region, _ := cmd.Flags().GetString("region")
+			return config.AddConfig(configName, providerType, credentialFlagValues(), endpoint, region, isDefault)
 		},
 	}
 
@@ -38,11 +39,9 @@ var (
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
 		PreRunE:      preRunE,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			itemName := args[0]
-			if err := config.SetDefaultConfig(itemName); err != nil {
-				cobra.CheckErr(err)
-			}
+			return config.SetDefaultConfig(itemName)
 		},
 	}
 
@@ -53,28 +52,43 @@ var (
 		Long:         `查看 DNS 服务商配置`,
 		SilenceUsage: true,
 		PreRunE:      preRunE,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			defaultName := config.GetDefaultConfigName()
 			configNameList := config.GetConfigNames()
+			items := make([]configItem, 0, len(configNameList))
 			for _, itemName := range configNameList {
-				if itemName == defaultName {
-					fmt.Printf("* %s (%s)\n", itemName, config.GetConfigType(itemName))
-				} else {
-					fmt.Printf("  %s (%s)\n", itemName, config.GetConfigType(itemName))
-				}
+				items = append(items, configItem{
+					Name:     itemName,
+					Type:     config.GetConfigType(itemName),
+					Default:  itemName == defaultName,
+					Endpoint: config.GetEndpoint(itemName),
+					Region:   config.GetRegion(itemName),
+				})
 			}
+			return printList(output, items, nil)
 		},
 	}
 )
 
+// configItem config list 中的一项，不包含认证信息
+type configItem struct {
+	Name     string `json:"name" table:"配置名"`
+	Type     string `json:"type" table:"DNS服务商"`
+	Default  bool   `json:"default" table:"默认"`
+	Endpoint string `json:"endpoint,omitempty" table:"访问地址"`
+	Region   string `json:"region,omitempty" table:"地域"`
+}
+
 func preRunE(cmd *cobra.Command, args []string) error {
 	return config.IsConfigFileUsed()
 }
 
 func init() {
 	cAddCmd.Flags().StringVarP(&providerType, "dnsapi", "p", "",
-		fmt.Sprintf("DNS 服务提供商, 取值为 (%s)", strings.Join(config.DnsServiceList, ", ")))
+		fmt.Sprintf("DNS 服务提供商, 取值为 (%s)", strings.Join(dnsapi.ProviderNames(), ", ")))
 
+	cAddCmd.Flags().String("endpoint", "", "API 访问地址，可以是主机名或完整的 URL，例如 dnspod.intl.tencentcloudapi.com")
+	cAddCmd.Flags().String("region", "", "地域，例如 cn-hangzhou、ap-singapore，Cloudflare 不需要")
 	cAddCmd.Flags().Bool("default", false, "是否设置为默认配置")
 	cCmd.AddCommand(cAddCmd)
 	cCmd.AddCommand(cListCmd)
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+
+	"github.com/liwanggui/dnscli-go/util"
 	"github.com/spf13/cobra"
 )
 
@@ -12,36 +14,88 @@ var (
 	}
 
 	dAddCmd = &cobra.Command{
-		Use:   "add DOMAIN",
-		Short: "增加管理域名",
+		Use:          "add DOMAIN",
+		Short:        "增加管理域名",
+		Long:         `将域名添加到 DNS 服务商，添加成功后需要到域名注册商处将 DNS 服务器修改为服务商分配的地址`,
+		Example:      `  dnscli domain add example.com`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+			client, err := createProvider()
+			if err != nil {
+				return err
+			}
+			domain, err := client.AddDomain(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			printDone("added %s ok\n", domain.Name)
+			if len(domain.NameServers) > 0 {
+				fmt.Println("请将域名的 DNS 服务器修改为:")
+				for _, ns := range domain.NameServers {
+					fmt.Println("  " + ns)
+				}
+			}
+			return nil
+		},
 	}
 
 	dDelCmd = &cobra.Command{
-		Use:   "del DOMAIN",
-		Short: "删除域名",
+		Use:          "del DOMAIN",
+		Aliases:      []string{"delete"},
+		Short:        "删除域名",
+		Long:         `从 DNS 服务商删除域名，域名下的所有解析记录会被一并删除`,
+		Example:      `  dnscli domain del example.com`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+			if !yes && !dryRun && !util.Confirm(fmt.Sprintf("确认删除域名 %s 及其所有解析记录?", args[0]), false, nil) {
+				fmt.Println("已取消")
+				return nil
+			}
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+			client, err := createProvider()
+			if err != nil {
+				return err
+			}
+			if err := client.DeleteDomain(ctx, args[0]); err != nil {
+				return err
+			}
+			printDone("deleted %s ok\n", args[0])
+			return nil
+		},
 	}
 
 	dListCmd = &cobra.Command{
-		Use:     "list",
-		Aliases: []string{"l", "ls"},
-		Short:   "查看域名列表",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:          "list",
+		Aliases:      []string{"l", "ls"},
+		Short:        "查看域名列表",
+		Example:      `  dnscli domain list -o json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
 			client, err := createProvider()
 			if err != nil {
-				cobra.CheckErr(err)
+				return err
 			}
-			domains, err := client.ListDomains()
+			domains, err := client.ListDomains(ctx)
 			if err != nil {
-				cobra.CheckErr(err)
-			}
-			for _, domain := range domains {
-				fmt.Println(domain)
+				return err
 			}
+			return printList(output, domains, nil)
 		},
 	}
 )
 
 func init() {
+	dDelCmd.Flags().BoolP("yes", "y", false, "跳过确认直接删除")
+
 	dCmd.AddCommand(dAddCmd)
 	dCmd.AddCommand(dDelCmd)
 	dCmd.AddCommand(dListCmd)
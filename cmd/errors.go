@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// 进程退出码，自动化脚本可根据退出码判断失败原因
+const (
+	ExitOK           = 0
+	ExitError        = 1  // 未分类的错误
+	ExitInvalidInput = 2  // 参数无效
+	ExitNotFound     = 3  // 域名或记录不存在
+	ExitAuthFailed   = 4  // 认证失败或没有权限
+	ExitRateLimited  = 5  // 被服务商限流
+	ExitConflict     = 6  // 资源已存在或冲突
+	ExitQuota        = 7  // 超出配额限制
+	ExitTimeout      = 8  // 执行超时
+	ExitUnsupported  = 9  // 服务商不支持该操作
+	ExitUnavailable  = 10 // 服务商暂时不可用
+	ExitDenied       = 11 // 操作被策略拒绝
+	ExitCanceled     = 130
+)
+
+// ExitCode 返回错误对应的进程退出码
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, dnsapi.ErrInvalidInput):
+		return ExitInvalidInput
+	case errors.Is(err, dnsapi.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, dnsapi.ErrAuthFailed):
+		return ExitAuthFailed
+	case errors.Is(err, dnsapi.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, dnsapi.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, dnsapi.ErrQuotaExceeded):
+		return ExitQuota
+	case errors.Is(err, dnsapi.ErrUnsupported):
+		return ExitUnsupported
+	case errors.Is(err, dnsapi.ErrUnavailable):
+		return ExitUnavailable
+	case errors.Is(err, dnsapi.ErrDenied):
+		return ExitDenied
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	case errors.Is(err, context.Canceled):
+		return ExitCanceled
+	}
+	return ExitError
+}
+
+// checkErr 输出错误信息并以错误对应的退出码退出，err 为 nil 时不做任何处理
+func checkErr(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(ExitCode(err))
+}
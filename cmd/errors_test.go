@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("other"), ExitError},
+		{dnsapi.ErrInvalidInput, ExitInvalidInput},
+		{dnsapi.ErrNotFound, ExitNotFound},
+		{dnsapi.ErrAuthFailed, ExitAuthFailed},
+		{dnsapi.ErrRateLimited, ExitRateLimited},
+		{dnsapi.ErrConflict, ExitConflict},
+		{dnsapi.ErrQuotaExceeded, ExitQuota},
+		{dnsapi.ErrUnsupported, ExitUnsupported},
+		{dnsapi.ErrUnavailable, ExitUnavailable},
+		{dnsapi.ErrDenied, ExitDenied},
+		{context.DeadlineExceeded, ExitTimeout},
+		{context.Canceled, ExitCanceled},
+		{dnsapi.NewError(dnsapi.ErrNotFound, "code", "message", nil), ExitNotFound},
+		{fmt.Errorf("获取记录失败: %w", dnsapi.Errorf(dnsapi.ErrConflict, "已存在")), ExitConflict},
+		{fmt.Errorf("请求失败: %w", dnsapi.NewError(context.Canceled, "ClientError.NetworkError", "", nil)), ExitCanceled},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, 应为 %d", tt.err, got, tt.want)
+		}
+	}
+}
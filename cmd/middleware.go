@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/config"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/util"
+)
+
+// apiStats --verbose 时统计的 API 调用情况
+var apiStats struct {
+	calls   int
+	failed  int
+	elapsed time.Duration
+}
+
+// middlewares 根据命令行参数和当前配置返回包装服务商客户端的中间件，第一个在最外层
+//
+// dry-run 在最外层，拦截所有修改；只读检查随后，被拒绝的修改不会发出请求；
+// 调用统计在重试之外，按命令发起的调用计数；调用日志在重试之内，每次请求都会输出。
+func middlewares() []dnsapi.Middleware {
+	var list []dnsapi.Middleware
+	if dryRun {
+		list = append(list, dnsapi.DryRun(printPlannedChange))
+	}
+	if readOnly || config.IsReadOnly(configName) {
+		list = append(list, dnsapi.ReadOnly())
+	}
+	if verbose {
+		list = append(list, dnsapi.Timing(func(call *dnsapi.Call, elapsed time.Duration, err error) {
+			apiStats.calls++
+			apiStats.elapsed += elapsed
+			if err != nil {
+				apiStats.failed++
+			}
+		}))
+	}
+
+	retryConfig := config.GetRetryConfig(configName)
+	if rootCmd.PersistentFlags().Changed("retries") {
+		retryConfig.Retries = retries
+	}
+	retryConfig.OnRetry = func(attempt int, delay time.Duration, err error) {
+		fmt.Fprintf(os.Stderr, "请求失败，%s 后进行第 %d 次重试: %v\n", delay.Round(time.Millisecond), attempt, err)
+	}
+	list = append(list, dnsapi.Retry(retryConfig))
+
+	if verbose {
+		list = append(list, dnsapi.Logging(func(format string, a ...any) {
+			fmt.Fprintf(os.Stderr, "[api] "+format+"\n", a...)
+		}))
+	}
+	return list
+}
+
+// printAPIStats --verbose 时在命令结束后输出 API 调用统计
+func printAPIStats() {
+	if !verbose || apiStats.calls == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[api] 共调用 %d 次，失败 %d 次，耗时 %s\n",
+		apiStats.calls, apiStats.failed, apiStats.elapsed.Round(time.Millisecond))
+}
+
+// printPlannedChange 输出 dry-run 模式下将要执行的修改，修改记录时只输出有变化的字段
+func printPlannedChange(change dnsapi.PlannedChange) {
+	fmt.Printf("[dry-run] %s\n", change.Call)
+	switch before, after := change.Before, change.After; {
+	case before != nil && after != nil:
+		names, oldValues := util.GetStructFieldNamesAndValues(before, "table", plannedExclFields)
+		_, newValues := util.GetStructFieldNamesAndValues(after, "table", plannedExclFields)
+		for i, name := range names {
+			if oldValues[i] != newValues[i] {
+				fmt.Printf("    %s: %q -> %q\n", name, oldValues[i], newValues[i])
+			}
+		}
+	case before != nil:
+		printPlannedRecord("-", before)
+	case after != nil:
+		printPlannedRecord("+", after)
+	}
+}
+
+// plannedExclFields dry-run 输出记录时忽略的字段
+var plannedExclFields = []string{"ID", "Domain", "Updated"}
+
+// printPlannedRecord 输出被添加或删除的记录中不为空的字段
+func printPlannedRecord(sign string, record *dnsapi.Record) {
+	names, values := util.GetStructFieldNamesAndValues(record, "table", plannedExclFields)
+	for i, name := range names {
+		if values[i] != "" && values[i] != "0" && values[i] != "false" {
+			fmt.Printf("  %s %s: %s\n", sign, name, values[i])
+		}
+	}
+}
+
+// printDone 输出修改成功的信息，dry-run 模式下修改没有执行，不输出
+func printDone(format string, a ...any) {
+	if dryRun {
+		return
+	}
+	fmt.Printf(format, a...)
+}
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/util"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的输出格式
+const (
+	outputTable  = "table"
+	outputWide   = "wide"
+	outputJSON   = "json"
+	outputYAML   = "yaml"
+	outputCSV    = "csv"
+	outputNDJSON = "ndjson"
+	// outputTemplate Go text/template 格式的前缀，如 template={{.name}}
+	outputTemplate = "template="
+)
+
+var outputFormats = []string{outputTable, outputWide, outputJSON, outputYAML, outputCSV, outputNDJSON, outputTemplate + "..."}
+
+// validOutput 判断输出格式是否支持，模板格式还会检查模板语法
+func validOutput(format string) error {
+	if text, ok := strings.CutPrefix(format, outputTemplate); ok {
+		_, err := parseTemplate(text)
+		return err
+	}
+	switch format {
+	case outputTable, outputWide, outputJSON, outputYAML, outputCSV, outputNDJSON:
+		return nil
+	}
+	return dnsapi.Errorf(dnsapi.ErrInvalidInput, "不支持的输出格式：%s，取值为 (%s)", format, strings.Join(outputFormats, ", "))
+}
+
+// printList 按指定格式输出列表，table 格式下不输出 exclField 中的字段，wide 格式输出全部字段，
+// 其他格式的字段名取自 json 标签
+func printList[T any](format string, items []T, exclField []string) error {
+	if err := validOutput(format); err != nil {
+		return err
+	}
+	switch format {
+	case outputTable:
+		printTable(os.Stdout, items, excludeFields(exclField))
+		return nil
+	case outputWide:
+		printTable(os.Stdout, items, excludeFields(nil))
+		return nil
+	case outputJSON:
+		if items == nil {
+			items = []T{}
+		}
+		return printJSON(os.Stdout, items)
+	case outputYAML:
+		if items == nil {
+			items = []T{}
+		}
+		return printYAML(os.Stdout, items)
+	}
+	return printItems(os.Stdout, format, items)
+}
+
+// printColumns 按指定格式输出列表，table、wide 和 csv 格式只按顺序输出 fields 中的字段，
+// 其他格式输出全部字段
+func printColumns[T any](format string, items []T, fields []string) error {
+	if err := validOutput(format); err != nil {
+		return err
+	}
+	switch format {
+	case outputTable, outputWide:
+		printTable(os.Stdout, items, selectFields(fields))
+		return nil
+	case outputCSV:
+		cw := csv.NewWriter(os.Stdout)
+		for i, item := range items {
+			n, v := selectFields(fields)(item, "json")
+			if i == 0 {
+				if err := cw.Write(n); err != nil {
+					return err
+				}
+			}
+			if err := cw.Write(v); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return printList(format, items, nil)
+}
+
+// fieldSelector 返回结构体中要输出的字段的标签名和值
+type fieldSelector func(item any, tagName string) (names, values []string)
+
+// excludeFields 返回输出 exclField 以外全部字段的 fieldSelector
+func excludeFields(exclField []string) fieldSelector {
+	return func(item any, tagName string) ([]string, []string) {
+		return util.GetStructFieldNamesAndValues(item, tagName, exclField)
+	}
+}
+
+// selectFields 返回按顺序只输出 fields 中字段的 fieldSelector
+func selectFields(fields []string) fieldSelector {
+	return func(item any, tagName string) ([]string, []string) {
+		return util.GetStructFieldsByName(item, tagName, fields)
+	}
+}
+
+// printTable 以表格形式输出结构体列表，输出的字段由 selector 决定，表头取自字段的 table 标签
+func printTable[T any](w io.Writer, items []T, selector fieldSelector) {
+	table := tablewriter.NewWriter(w)
+	for i, item := range items {
+		n, v := selector(item, "table")
+		if i == 0 {
+			table.SetHeader(n)
+		}
+		table.Append(v)
+	}
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+// printJSON 以缩进的 JSON 格式输出
+func printJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printYAML 以 YAML 格式输出，字段名和顺序与 JSON 相同
+//
+// 先编码为 JSON 再作为 YAML 解析，从而复用 json 标签，解析后去掉 JSON 的引号和括号风格。
+func printYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	plainStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// plainStyle 将节点改为 YAML 的默认风格，YAML 1.1 中会被当作布尔值的字符串仍然加引号
+func plainStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		switch strings.ToLower(node.Value) {
+		case "y", "n", "yes", "no", "on", "off":
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+	for _, c := range node.Content {
+		plainStyle(c)
+	}
+}
+
+// printItems 以 csv、ndjson 或模板格式输出列表，字段名取自 json 标签
+func printItems[T any](w io.Writer, format string, items []T) error {
+	if text, ok := strings.CutPrefix(format, outputTemplate); ok {
+		return printTemplate(w, text, items)
+	}
+	if format == outputNDJSON {
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return printCSV(w, items)
+}
+
+// printCSV 以 CSV 格式输出，第一行为字段名，列及顺序取自结构体的 json 标签，与数据无关，
+// 切片以逗号连接，结构体等其他值为紧凑的 JSON，空值输出为空
+func printCSV[T any](w io.Writer, items []T) error {
+	columns := csvColumns(reflect.TypeFor[T](), nil)
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(columns))
+	for _, c := range columns {
+		header = append(header, c.name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, item := range items {
+		v := reflect.Indirect(reflect.ValueOf(item))
+		record := make([]string, 0, len(columns))
+		for _, c := range columns {
+			var value string
+			// 嵌入的结构体指针为 nil 时字段值为空
+			if field, err := v.FieldByIndexErr(c.index); err == nil {
+				if value, err = csvValue(field); err != nil {
+					return err
+				}
+			}
+			record = append(record, value)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvColumn CSV 中的一列，index 为字段在结构体中的位置
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns 按声明顺序返回结构体的 json 字段名，展开匿名嵌入的结构体
+func csvColumns(t reflect.Type, index []int) []csvColumn {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var columns []csvColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		fieldIndex := append(slices.Clone(index), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			columns = append(columns, csvColumns(ft, fieldIndex)...)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		columns = append(columns, csvColumn{name: name, index: fieldIndex})
+	}
+	return columns
+}
+
+// csvValue 返回字段在 CSV 中的值
+func csvValue(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map:
+		if v.IsNil() {
+			return "", nil
+		}
+	case reflect.Slice:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, fmt.Sprint(v.Index(i).Interface()))
+		}
+		return strings.Join(items, ","), nil
+	case reflect.Struct, reflect.Array:
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
+	data, err := json.Marshal(v.Interface())
+	return string(data), err
+}
+
+// parseTemplate 解析 template= 格式的模板
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, dnsapi.Errorf(dnsapi.ErrInvalidInput, "输出模板格式错误: %v", err)
+	}
+	return tmpl, nil
+}
+
+// printTemplate 使用 Go text/template 逐条输出，每条之后换行，模板中的字段名取自 json 标签，如 {{.name}}
+func printTemplate[T any](w io.Writer, text string, items []T) error {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		if err := tmpl.Execute(w, v); err != nil {
+			return dnsapi.Errorf(dnsapi.ErrInvalidInput, "输出模板执行失败: %v", err)
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordDetail record get 输出的记录详情，在记录的基础上增加完整域名
+type recordDetail struct {
+	*dnsapi.Record
+	FQDN string `json:"fqdn"`
+}
+
+// printRecord 按指定格式将单条记录输出到 w，table 格式下每行为一个字段，不输出 exclField 中的字段，
+// wide 格式输出全部字段
+func printRecord(w io.Writer, format string, record *dnsapi.Record, exclField []string) error {
+	if err := validOutput(format); err != nil {
+		return err
+	}
+	detail := recordDetail{Record: record, FQDN: record.FQDN()}
+	switch format {
+	case outputTable:
+	case outputWide:
+		exclField = nil
+	case outputJSON:
+		return printJSON(w, detail)
+	case outputYAML:
+		return printYAML(w, detail)
+	default:
+		return printItems(w, format, []recordDetail{detail})
+	}
+	names, values := util.GetStructFieldNamesAndValues(record, "table", exclField)
+	table := tablewriter.NewWriter(w)
+	for i, name := range names {
+		table.Append([]string{name, values[i]})
+	}
+	table.Append([]string{"完整域名", record.FQDN()})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+var testRecords = []dnsapi.Record{
+	{ID: "1", Domain: "example.com", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+	{ID: "2", Domain: "example.com", Name: "@", Type: "MX", Value: "mx.example.com", TTL: 600, Priority: 10, Remark: "邮件",
+		MX: &dnsapi.MX{Preference: 10, Exchange: "mx.example.com"}},
+}
+
+func TestPrintCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		print func(w *bytes.Buffer) error
+		want  string
+	}{
+		{
+			"记录的列与数据无关",
+			func(w *bytes.Buffer) error { return printCSV(w, testRecords) },
+			"id,domain,name,type,value,ttl,line,priority,weight,proxied,status,remark,updated,srv,caa,mx\n" +
+				"1,example.com,www,A,192.0.2.1,600,,0,0,false,,,,,,\n" +
+				`2,example.com,@,MX,mx.example.com,600,,10,0,false,,邮件,,,,"{""preference"":10,""exchange"":""mx.example.com""}"` + "\n",
+		},
+		{
+			"没有数据时只输出表头",
+			func(w *bytes.Buffer) error { return printCSV(w, []dnsapi.Line{}) },
+			"code,name,parent\n",
+		},
+		{
+			"切片以逗号连接",
+			func(w *bytes.Buffer) error {
+				return printCSV(w, []dnsapi.Domain{{ID: "1", Name: "example.com", NameServers: []string{"ns1.example.net", "ns2.example.net"}}})
+			},
+			"id,name,status,record_count,plan,name_servers,created\n" +
+				`1,example.com,,0,,"ns1.example.net,ns2.example.net",` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.print(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("输出 = %q, 应为 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintYAML(t *testing.T) {
+	var buf bytes.Buffer
+	records := []dnsapi.Record{{ID: "1", Name: "www", Type: "TXT", Value: "yes", TTL: 600}}
+	if err := printYAML(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	want := "- id: \"1\"\n  domain: \"\"\n  name: www\n  type: TXT\n  value: \"yes\"\n  ttl: 600\n"
+	if got := buf.String(); got != want {
+		t.Errorf("输出 = %q, 应为 %q", got, want)
+	}
+}
+
+func TestPrintItems(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{"ndjson", outputNDJSON,
+			`{"id":"1","domain":"example.com","name":"www","type":"A","value":"192.0.2.1","ttl":600}` + "\n" +
+				`{"id":"2","domain":"example.com","name":"@","type":"MX","value":"mx.example.com","ttl":600,"priority":10,"remark":"邮件","mx":{"preference":10,"exchange":"mx.example.com"}}` + "\n"},
+		{"模板", "template={{.name}} {{.ttl}} {{.mx.exchange}}", "www 600 <no value>\n@ 600 mx.example.com\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printItems(&buf, tt.format, testRecords); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("输出 = %q, 应为 %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := printItems(&bytes.Buffer{}, "template={{.name.bogus}}", testRecords); !errors.Is(err, dnsapi.ErrInvalidInput) {
+		t.Errorf("模板执行失败时错误 = %v, 应为 ErrInvalidInput", err)
+	}
+	if err := validOutput("template={{.name"); !errors.Is(err, dnsapi.ErrInvalidInput) {
+		t.Errorf("模板格式错误时错误 = %v, 应为 ErrInvalidInput", err)
+	}
+}
+
+func TestPrintRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record dnsapi.Record
+		want   string
+	}{
+		{"主机记录", testRecords[0], "www.example.com"},
+		{"@ 为域名本身", testRecords[1], "example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printRecord(&buf, outputJSON, &tt.record, nil); err != nil {
+				t.Fatal(err)
+			}
+			var detail map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &detail); err != nil {
+				t.Fatal(err)
+			}
+			if detail["fqdn"] != tt.want || detail["id"] != tt.record.ID {
+				t.Errorf("json 输出 = %v, fqdn 应为 %s", detail, tt.want)
+			}
+
+			buf.Reset()
+			if err := printRecord(&buf, "template={{.fqdn}}", &tt.record, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want+"\n" {
+				t.Errorf("模板输出 = %q, 应为 %q", got, tt.want+"\n")
+			}
+
+			buf.Reset()
+			if err := printRecord(&buf, outputCSV, &tt.record, nil); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 || !strings.HasSuffix(lines[0], ",fqdn") || !strings.HasSuffix(lines[1], ","+tt.want) {
+				t.Errorf("csv 输出 = %q, 最后一列应为 fqdn", buf.String())
+			}
+
+			buf.Reset()
+			if err := printRecord(&buf, outputTable, &tt.record, nil); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), "完整域名") || !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("表格输出 = %q, 应包含完整域名", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/spf13/pflag"
+
+	// 注册支持的 DNS 服务商
+	_ "github.com/liwanggui/dnscli-go/dnsapi/aliyun"
+	_ "github.com/liwanggui/dnscli-go/dnsapi/cloudflare"
+	_ "github.com/liwanggui/dnscli-go/dnsapi/local"
+	_ "github.com/liwanggui/dnscli-go/dnsapi/tencent"
+)
+
+// credentialFlags 认证信息键名与命令行参数值的对应关系
+var credentialFlags = make(map[string]*string)
+
+// addCredentialFlags 根据已注册服务商的认证信息添加命令行参数，
+// 多个服务商使用相同键名时共用同一个参数
+func addCredentialFlags(flags *pflag.FlagSet) {
+	var keys []string
+	usages := make(map[string][]string)
+	for _, p := range dnsapi.Providers() {
+		for _, field := range p.Credentials {
+			if _, ok := usages[field.Key]; !ok {
+				keys = append(keys, field.Key)
+			}
+			usages[field.Key] = append(usages[field.Key], field.Description)
+		}
+	}
+	for _, key := range keys {
+		credentialFlags[key] = flags.String(credentialFlagName(key), "", strings.Join(usages[key], " / "))
+	}
+}
+
+// credentialFlagValues 返回命令行中指定的认证信息
+func credentialFlagValues() map[string]string {
+	values := make(map[string]string)
+	for key, value := range credentialFlags {
+		if *value != "" {
+			values[key] = *value
+		}
+	}
+	return values
+}
+
+// credentialFlagName 返回认证信息对应的命令行参数名
+func credentialFlagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
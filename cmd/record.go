@@ -1,13 +1,15 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
 	"fmt"
-	"github.com/liwanggui/dnscli-go/config"
 	"github.com/liwanggui/dnscli-go/dnsapi"
 	"github.com/liwanggui/dnscli-go/util"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -19,146 +21,865 @@ var (
 	}
 
 	rAddCmd = &cobra.Command{
-		Use:          "create DOMAIN RECORD_NAME RECORD_TYPE RECORD_VALUE",
-		Aliases:      []string{"a", "add"},
-		Short:        "创建解析记录",
-		Example:      `  dnscli create example.com www A 1.1.1.1`,
+		Use:     "create DOMAIN RECORD_NAME RECORD_TYPE RECORD_VALUE",
+		Aliases: []string{"a", "add"},
+		Short:   "创建解析记录",
+		Example: `  dnscli record create example.com www A 1.1.1.1
+  dnscli record create example.com @ MX mx.example.com --priority 10
+  dnscli record create example.com _sip._tcp SRV sip.example.com --priority 10 --srv-weight 5 --srv-port 5060
+  dnscli record create example.com @ CAA letsencrypt.org --caa-tag issue`,
 		Args:         cobra.ExactArgs(4),
 		SilenceUsage: true,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
 			client, err := createProvider()
 			if err != nil {
-				cobra.CheckErr(err)
+				return err
 			}
 			param := dnsapi.CreateParameter(args[0])
 			param.Name = args[1]
 			param.Type = args[2]
 			param.Value = args[3]
-			if err := dnsapi.ValidRecordType(param.Type); err != nil {
-				cobra.CheckErr(err)
+			param.Proxied, _ = cmd.Flags().GetBool("proxied")
+			param.TTL, _ = cmd.Flags().GetInt("ttl")
+			param.Line, _ = cmd.Flags().GetString("line")
+			param.Remark, _ = cmd.Flags().GetString("remark")
+			param.Weight, _ = cmd.Flags().GetInt("weight")
+			param.ZeroWeight = cmd.Flags().Changed("weight") && param.Weight == 0
+
+			caps := client.Capabilities()
+			if err := checkFlags(cmd, caps); err != nil {
+				return err
+			}
+			if err := caps.ValidRecordType(param.Type); err != nil {
+				return err
+			}
+			if err := setRecordData(cmd, param); err != nil {
+				return err
+			}
+			if err := resolveLine(ctx, cmd, client, param); err != nil {
+				return err
 			}
-			if err := client.AddRecord(param); err != nil {
-				cobra.CheckErr(err)
+			if err := client.AddRecord(ctx, param); err != nil {
+				return err
 			}
+			printDone("created %s ok\n", param.ID)
+			return nil
 		},
 	}
 
 	rDelCmd = &cobra.Command{
-		Use:     "delete DOMAIN RECORD_ID...",
+		Use:     "delete DOMAIN [RECORD_ID...]",
 		Aliases: []string{"d", "del"},
-		Short:   "删除解析记录",
-		Example: "  dnscli record delete 1234567890",
-		Args:    cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		Short:   "删除解析记录，服务商支持时使用批量接口",
+		Long: `按记录 ID 删除解析记录，或者使用 --name、--type、--value、--line 查询要删除的记录，
+匹配到多条记录时需要指定 --all`,
+		Example: `  dnscli record delete example.com 1234567890 1234567891
+  dnscli record delete example.com --name www --type A --value 1.1.1.1
+  dnscli record delete example.com --name test --all`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if hasSelectors(cmd, true) {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
 			client, err := createProvider()
 			if err != nil {
-				cobra.CheckErr(err)
+				return err
 			}
-			param := dnsapi.CreateParameter(args[0])
-			for _, rid := range args[1:] {
-				param.ID = rid
-				if err := client.DeleteRecord(param); err != nil {
-					cobra.CheckErr(err)
+			ids := args[1:]
+			if hasSelectors(cmd, true) {
+				records, err := selectRecords(ctx, cmd, client, args[0])
+				if err != nil {
+					return err
+				}
+				for _, r := range records {
+					ids = append(ids, r.ID)
 				}
-				fmt.Printf("deleted %s ok\n", rid)
 			}
+			changes := make([]dnsapi.Change, 0, len(ids))
+			for _, rid := range ids {
+				param := dnsapi.CreateParameter(args[0])
+				param.ID = rid
+				changes = append(changes, dnsapi.Change{Action: dnsapi.ChangeDelete, Param: param})
+			}
+			results, err := dnsapi.ApplyChanges(ctx, client, args[0], changes)
+			if err != nil {
+				return err
+			}
+			return reportResults(results, "deleted")
 		},
 	}
 
 	rUpdateCmd = &cobra.Command{
-		Use:     "update DOMAIN RECORD_ID RECORD_NAME RECORD_TYPE RECORD_VALUE",
+		Use:     "update DOMAIN [RECORD_ID RECORD_NAME RECORD_TYPE] [RECORD_VALUE]",
 		Aliases: []string{"u"},
 		Short:   "更新解析记录",
-		Long:    `查询 DNS 服务商账号下指定域名的解析记录`,
-		Run: func(cmd *cobra.Command, args []string) {
+		Long: `按记录 ID 更新解析记录，或者使用 --name、--type、--value、--line 查询要更新的记录，
+匹配到多条记录时需要指定 --all。
+
+使用查询条件时只需要指定新的记录值，省略时保留原记录值，主机记录、记录类型和线路保持不变，
+--ttl、--remark、--weight 等参数未指定的字段也保持原值。
+
+按记录 ID 更新时 --line 表示新的线路，因此与 delete 不同，单独指定 --line 不会按查询条件更新，
+需要和 --name、--type、--value 或 --all 一起使用，此时按线路过滤要更新的记录。`,
+		Example: `  dnscli record update example.com 1234567890 www A 1.1.1.1
+  dnscli record update example.com 2.2.2.2 --name www --type A --value 1.1.1.1
+  dnscli record update example.com --name www --type A --all --ttl 600`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// --line 在按记录 ID 更新时是新的线路，不单独作为查询条件
+			if hasSelectors(cmd, false) {
+				return cobra.RangeArgs(1, 2)(cmd, args)
+			}
+			return cobra.ExactArgs(5)(cmd, args)
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
 			client, err := createProvider()
 			if err != nil {
-				cobra.CheckErr(err)
+				return err
 			}
+			caps := client.Capabilities()
+			if err := checkFlags(cmd, caps); err != nil {
+				return err
+			}
+
+			if hasSelectors(cmd, false) {
+				records, err := selectRecords(ctx, cmd, client, args[0])
+				if err != nil {
+					return err
+				}
+				changes := make([]dnsapi.Change, 0, len(records))
+				for _, r := range records {
+					param := r.Parameter()
+					if len(args) > 1 {
+						param.Value = args[1]
+					}
+					applyRecordFlags(cmd, param)
+					if err := setRecordData(cmd, param); err != nil {
+						return err
+					}
+					changes = append(changes, dnsapi.Change{Action: dnsapi.ChangeUpdate, Param: param})
+				}
+				results, err := dnsapi.ApplyChanges(ctx, client, args[0], changes)
+				if err != nil {
+					return err
+				}
+				return reportResults(results, "updated")
+			}
+
 			param := dnsapi.CreateParameter(args[0])
 			param.ID = args[1]
 			param.Name = args[2]
 			param.Type = args[3]
 			param.Value = args[4]
-			param.Proxied, _ = cmd.Flags().GetBool("proxied")
-			param.TTL, _ = cmd.Flags().GetInt("ttl")
 			param.Line, _ = cmd.Flags().GetString("line")
+			if err := caps.ValidRecordType(param.Type); err != nil {
+				return err
+			}
+			if err := resolveLine(ctx, cmd, client, param); err != nil {
+				return err
+			}
+			return updateRecord(ctx, cmd, client, param)
+		},
+	}
+
+	rSetCmd = &cobra.Command{
+		Use:   "set DOMAIN RECORD_NAME RECORD_TYPE RECORD_VALUE...",
+		Short: "设置同名同类型的解析记录为指定的记录值，按需创建、修改或删除记录",
+		Long: `设置主机记录和记录类型相同的解析记录，使其正好为指定的一个或多个记录值：
+记录值已存在的记录保留，多余的记录修改为缺少的记录值或者删除，仍然缺少的记录值再创建。
+指定 --ttl、--remark、--weight、--proxied 时保留的记录也会按这些参数修改，未指定时保持原值。
+
+指定 --line 时只处理该线路的记录，否则处理所有线路的记录。
+记录已经一致时输出 no change，可以在脚本中重复执行。`,
+		Example: `  dnscli record set example.com www A 1.1.1.1
+  dnscli record set example.com www A 1.1.1.1 2.2.2.2 --ttl 600
+  dnscli record set example.com @ MX "10 mx1.example.com" "20 mx2.example.com"`,
+		Args:         cobra.MinimumNArgs(4),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+			client, err := createProvider()
+			if err != nil {
+				return err
+			}
+			caps := client.Capabilities()
+			if err := checkFlags(cmd, caps); err != nil {
+				return err
+			}
+			if err := caps.ValidRecordType(args[2]); err != nil {
+				return err
+			}
+
+			base := dnsapi.CreateParameter(args[0])
+			base.Name = args[1]
+			base.Type = args[2]
+			base.Line, _ = cmd.Flags().GetString("line")
+			applyRecordFlags(cmd, base)
+			if err := resolveLine(ctx, cmd, client, base); err != nil {
+				return err
+			}
+			desired := make([]*dnsapi.Parameter, 0, len(args)-3)
+			for _, value := range args[3:] {
+				param := *base
+				param.Value = value
+				if err := setRecordData(cmd, &param); err != nil {
+					return err
+				}
+				desired = append(desired, &param)
+			}
+
+			existing, err := listRecordSet(ctx, client, base)
+			if err != nil {
+				return err
+			}
+			changes := dnsapi.PlanRecordSet(existing, desired, func(p *dnsapi.Parameter) { applyRecordFlags(cmd, p) })
+			if len(changes) == 0 {
+				fmt.Println("no change")
+				return nil
+			}
+			results, err := dnsapi.ApplyChanges(ctx, client, args[0], changes)
+			if err != nil {
+				return err
+			}
+			return reportResults(results, "")
+		},
+	}
 
-			if err := dnsapi.ValidRecordType(param.Type); err != nil {
-				cobra.CheckErr(err)
+	rEnableCmd = &cobra.Command{
+		Use:          "enable DOMAIN RECORD_ID...",
+		Aliases:      []string{"resume"},
+		Short:        "启用解析记录",
+		Example:      "  dnscli record enable example.com 1234567890",
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE:         runSetRecordStatus(dnsapi.StatusEnable, "enabled"),
+	}
+
+	rDisableCmd = &cobra.Command{
+		Use:          "disable DOMAIN RECORD_ID...",
+		Aliases:      []string{"pause"},
+		Short:        "暂停解析记录，记录保留但不再生效",
+		Example:      "  dnscli record disable example.com 1234567890",
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE:         runSetRecordStatus(dnsapi.StatusDisable, "disabled"),
+	}
+
+	rGetCmd = &cobra.Command{
+		Use:   "get DOMAIN RECORD_ID",
+		Short: "查询解析记录详情",
+		Long:  `查询单条解析记录的完整信息，包括线路、状态、备注、权重、代理、更新时间和完整域名`,
+		Example: `  dnscli record get example.com 1234567890
+  dnscli record get example.com 1234567890 -o json`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+			client, err := createProvider()
+			if err != nil {
+				return err
 			}
-			if err := client.UpdateRecord(param); err != nil {
-				cobra.CheckErr(err)
+			param := dnsapi.CreateParameter(args[0])
+			param.ID = args[1]
+			record, err := client.GetRecord(ctx, param)
+			if err != nil {
+				return err
 			}
-			fmt.Printf("updated %s ok\n", args[1])
+			return printRecord(os.Stdout, output, record, hiddenFields(client.Capabilities()))
+		},
+	}
+
+	rLinesCmd = &cobra.Command{
+		Use:          "lines DOMAIN",
+		Short:        "查询域名支持的解析线路",
+		Long:         `查询域名当前套餐支持的解析线路，--line 参数可以使用线路代码或线路名`,
+		Example:      `  dnscli record lines example.com`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+			client, err := createProvider()
+			if err != nil {
+				return err
+			}
+			if !client.Capabilities().Lines {
+				return dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持解析线路")
+			}
+			lines, err := client.ListLines(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return printList(output, lines, nil)
+		},
+	}
+
+	rWeightsCmd = &cobra.Command{
+		Use:   "weights DOMAIN RECORD_NAME",
+		Short: "查看主机记录下各记录值的权重和流量占比",
+		Long: `查看主机记录下各记录值的权重和流量占比，按记录类型和线路分组计算，暂停的记录不参与分配。
+
+同组记录都没有设置权重时平均分配；有记录设置了权重时按权重比例分配，
+权重为 0 或没有设置权重的记录不分配流量，与 DNSPod 权重为 0 时不返回该记录一致。`,
+		Example:      `  dnscli record weights example.com www -t A`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+			client, err := createProvider()
+			if err != nil {
+				return err
+			}
+			caps := client.Capabilities()
+			if !caps.Weight {
+				return dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持记录权重")
+			}
+			param := dnsapi.CreateParameter(args[0])
+			param.Name = args[1]
+			param.Type, _ = cmd.Flags().GetString("type")
+			if param.Type != "" {
+				if err := caps.ValidRecordType(param.Type); err != nil {
+					return err
+				}
+			}
+			records, err := client.ListRecords(ctx, param)
+			if err != nil {
+				return err
+			}
+			// 服务商按关键字模糊匹配主机记录，这里只保留名称完全相同的记录
+			filtered := make([]dnsapi.Record, 0, len(records))
+			for _, r := range records {
+				if r.Name == param.Name {
+					filtered = append(filtered, r)
+				}
+			}
+			var exclField []string
+			if !caps.Lines {
+				exclField = append(exclField, "Line")
+			}
+			return printList(output, weightShares(filtered), exclField)
 		},
 	}
 
 	rListCmd = &cobra.Command{
-		Use:          "list DOMAIN",
-		Aliases:      []string{"l", "ls"},
-		Short:        "查询解析记录",
-		Long:         `查询 DNS 服务商账号下指定域名的解析记录`,
-		Example:      `  dnscli record list example.com`,
+		Use:     "list DOMAIN",
+		Aliases: []string{"l", "ls"},
+		Short:   "查询解析记录",
+		Long:    `查询 DNS 服务商账号下指定域名的解析记录`,
+		Example: `  dnscli record list example.com
+  dnscli record list example.com --sort-by value --columns name,type,value,ttl`,
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
 			client, err := createProvider()
 			if err != nil {
-				cobra.CheckErr(err)
+				return err
+			}
+			sortBy, _ := cmd.Flags().GetString("sort-by")
+			if _, ok := recordSortKeys[sortBy]; sortBy != "" && !ok {
+				return dnsapi.Errorf(dnsapi.ErrInvalidInput, "不支持的排序字段：%s，取值为 (%s)", sortBy, strings.Join(recordSortNames, ", "))
+			}
+			columnNames, _ := cmd.Flags().GetStringSlice("columns")
+			columns, err := recordColumns(columnNames)
+			if err != nil {
+				return err
 			}
+
 			param := dnsapi.CreateParameter(args[0])
 			param.Name, _ = cmd.Flags().GetString("name")
 			param.Type, _ = cmd.Flags().GetString("type")
 			param.Value, _ = cmd.Flags().GetString("value")
 			param.Line, _ = cmd.Flags().GetString("line")
+			param.Remark, _ = cmd.Flags().GetString("remark")
+
+			caps := client.Capabilities()
+			if err := checkFlags(cmd, caps); err != nil {
+				return err
+			}
 			if param.Type != "" {
-				if err := dnsapi.ValidRecordType(param.Type); err != nil {
-					cobra.CheckErr(err)
+				if err := caps.ValidRecordType(param.Type); err != nil {
+					return err
 				}
 			}
-			records, err := client.ListRecords(param)
+			if err := resolveLine(ctx, cmd, client, param); err != nil {
+				return err
+			}
+			records, err := client.ListRecords(ctx, param)
 			if err != nil {
-				cobra.CheckErr(err)
+				return err
 			}
-			exclField := make([]string, 1)
-			cName := getCurrentConfigName()
-			cType := config.GetConfigType(cName)
-			switch cType {
-			case "aliyun":
-				exclField = append(exclField, "Proxied", "Updated")
-			case "tencent":
-				exclField = append(exclField, "Proxied")
-			case "cloudflare":
-				exclField = append(exclField, "Line")
+			if param.Remark != "" {
+				records = filterByRemark(records, param.Remark)
 			}
+			sortRecords(records, sortBy)
+			exclField := hiddenFields(caps)
 
-			fmt.Println("记录数:", len(records))
-			table := tablewriter.NewWriter(os.Stdout)
-			for i, record := range records {
-				n, v := util.GetStructFieldNamesAndValues(record, "table", exclField)
-				if i == 0 {
-					table.SetHeader(n)
-				}
-				table.Append(v)
+			fmt.Fprintln(os.Stderr, "记录数:", len(records))
+			if len(columns) > 0 {
+				err = printColumns(output, records, columns)
+			} else {
+				err = printList(output, records, exclField)
 			}
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.Render()
+			return err
 		},
 	}
 )
 
 func init() {
-	rCmd.PersistentFlags().Int("ttl", 0, "解析记录 TTL 值，免费 DNS 解析基本都不支持小于 600s")
-	rCmd.PersistentFlags().String("line", "", "解析线路名，需要 DNS 服务商提供支持")
+	rCmd.PersistentFlags().Int("ttl", 0, "解析记录 TTL 值，免费 DNS 解析基本都不支持小于 600s，Cloudflare 可以使用 1 表示自动")
+	rCmd.PersistentFlags().String("line", "", "解析线路代码或线路名，需要 DNS 服务商提供支持，可用线路见 record lines")
 	rCmd.PersistentFlags().Bool("proxied", false, "是否启动 CND 加速，仅 cloudflare 使用 (default: false)")
+	rCmd.PersistentFlags().String("remark", "", "解析记录备注，查询时按备注包含的内容过滤")
+
+	for _, c := range []*cobra.Command{rAddCmd, rUpdateCmd, rSetCmd} {
+		c.Flags().Int("weight", 0, "同名同类型多条记录的负载均衡权重，需要 DNS 服务商提供支持")
+		c.Flags().Int("priority", 0, "MX、SRV 记录的优先级，也可以直接写在记录值中")
+		c.Flags().Int("srv-weight", 0, "SRV 记录的权重，指定后记录值只需要填写目标地址")
+		c.Flags().Int("srv-port", 0, "SRV 记录的端口，指定后记录值只需要填写目标地址")
+		c.Flags().Int("caa-flags", 0, "CAA 记录的标志")
+		c.Flags().String("caa-tag", "", "CAA 记录的标签，取值 issue、issuewild、iodef，指定后记录值只需要填写 CA 域名或 URL")
+	}
 
 	rListCmd.Flags().StringP("name", "n", "", "解析记录名")
 	rListCmd.Flags().StringP("type", "t", "", fmt.Sprintf("解析记录类型, 取值(%s)", strings.Join(dnsapi.RecordTypes, ",")))
 	rListCmd.Flags().StringP("value", "v", "", "解析记录值")
+	rListCmd.Flags().StringSlice("columns", nil,
+		fmt.Sprintf("table、wide、csv 格式输出的列及顺序，多个以逗号分隔，取值(%s)", strings.Join(recordColumnNames(), ",")))
+	rListCmd.Flags().String("sort-by", "", fmt.Sprintf("排序字段，取值(%s)，主机记录按自然顺序、记录值按 IP 地址大小排序", strings.Join(recordSortNames, ",")))
+	for _, c := range []*cobra.Command{rDelCmd, rUpdateCmd} {
+		c.Flags().StringP("name", "n", "", "按主机记录查询要操作的解析记录")
+		c.Flags().StringP("type", "t", "", "按记录类型查询要操作的解析记录")
+		c.Flags().StringP("value", "v", "", "按记录值查询要操作的解析记录")
+		c.Flags().Bool("all", false, "查询条件匹配到多条记录时全部操作")
+	}
+	rWeightsCmd.Flags().StringP("type", "t", "", "解析记录类型，默认为全部类型")
 
 	rCmd.AddCommand(rAddCmd)
 	rCmd.AddCommand(rDelCmd)
 	rCmd.AddCommand(rListCmd)
+	rCmd.AddCommand(rGetCmd)
 	rCmd.AddCommand(rUpdateCmd)
+	rCmd.AddCommand(rSetCmd)
+	rCmd.AddCommand(rEnableCmd)
+	rCmd.AddCommand(rDisableCmd)
+	rCmd.AddCommand(rLinesCmd)
+	rCmd.AddCommand(rWeightsCmd)
+}
+
+// runSetRecordStatus 返回将解析记录设置为指定状态的命令处理函数，done 为成功后输出的动作名称
+func runSetRecordStatus(status, done string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+		client, err := createProvider()
+		if err != nil {
+			return err
+		}
+		if !client.Capabilities().Status {
+			return dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持启用/暂停解析记录")
+		}
+		param := dnsapi.CreateParameter(args[0])
+		param.Status = status
+		for _, rid := range args[1:] {
+			param.ID = rid
+			if err := client.SetRecordStatus(ctx, param); err != nil {
+				return err
+			}
+			printDone("%s %s ok\n", done, rid)
+		}
+		return nil
+	}
+}
+
+// setRecordData 根据 --priority、--srv-*、--caa-* 参数设置 SRV、CAA、MX 记录的结构化数据，
+// 未指定这些参数时从记录值中解析，解析后的记录值为统一的格式
+func setRecordData(cmd *cobra.Command, param *dnsapi.Parameter) error {
+	flags := cmd.Flags()
+	if flags.Changed("priority") {
+		param.Priority, _ = flags.GetInt("priority")
+	}
+	srv := flags.Changed("srv-weight") || flags.Changed("srv-port")
+	caa := flags.Changed("caa-flags") || flags.Changed("caa-tag")
+
+	if srv && param.Type != "SRV" {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "--srv-weight 和 --srv-port 参数仅用于 SRV 记录")
+	}
+	if caa && param.Type != "CAA" {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "--caa-flags 和 --caa-tag 参数仅用于 CAA 记录")
+	}
+	if flags.Changed("priority") && param.Type != "MX" && param.Type != "SRV" {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "--priority 参数仅用于 MX 和 SRV 记录")
+	}
+
+	switch {
+	case srv:
+		param.SRV = &dnsapi.SRV{Priority: param.Priority, Target: param.Value}
+		param.SRV.Weight, _ = flags.GetInt("srv-weight")
+		param.SRV.Port, _ = flags.GetInt("srv-port")
+	case caa:
+		param.CAA = &dnsapi.CAA{Value: param.Value}
+		param.CAA.Flags, _ = flags.GetInt("caa-flags")
+		param.CAA.Tag, _ = flags.GetString("caa-tag")
+		if param.CAA.Tag == "" {
+			return dnsapi.Errorf(dnsapi.ErrInvalidInput, "CAA 记录需要指定 --caa-tag 参数")
+		}
+	}
+	return param.ParseData()
+}
+
+// reportResults 输出批量修改中每条记录的执行结果，有失败时返回第一个失败的错误，
+// 失败的数量会附加在错误信息中，done 为空时输出修改的简要说明
+func reportResults(results []dnsapi.ChangeResult, done string) error {
+	var failed []error
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(os.Stderr, "%s failed: %v\n", r.Change, r.Err)
+			failed = append(failed, r.Err)
+		case done == "":
+			printDone("%s ok\n", r.Change)
+		default:
+			printDone("%s %s ok\n", done, r.Change.Param.ID)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d/%d 条记录执行失败: %w", len(failed), len(results), failed[0])
+	}
+	return nil
+}
+
+// resolveLine 校验 --line 参数是否为域名支持的解析线路，并将线路名转换为线路代码
+func resolveLine(ctx context.Context, cmd *cobra.Command, client dnsapi.DNSAPI, param *dnsapi.Parameter) error {
+	if !cmd.Flags().Changed("line") {
+		return nil
+	}
+	lines, err := client.ListLines(ctx, param.Domain)
+	if err != nil {
+		return err
+	}
+	line, err := dnsapi.FindLine(lines, param.Line)
+	if err != nil {
+		return fmt.Errorf("%w，可以使用 dnscli record lines %s 查看支持的线路", err, param.Domain)
+	}
+	param.Line = line.Code
+	return nil
+}
+
+// hasSelectors 判断是否指定了查询记录的条件，includeLine 为 false 时 --line 不作为查询条件
+func hasSelectors(cmd *cobra.Command, includeLine bool) bool {
+	flags := cmd.Flags()
+	if includeLine && flags.Changed("line") {
+		return true
+	}
+	return flags.Changed("name") || flags.Changed("type") || flags.Changed("value") || flags.Changed("all")
+}
+
+// selectRecords 按 --name、--type、--value、--line 查询要操作的解析记录并输出到标准错误，
+// 没有匹配的记录时返回 ErrNotFound，匹配到多条记录且没有指定 --all 时返回 ErrInvalidInput
+func selectRecords(ctx context.Context, cmd *cobra.Command, client dnsapi.DNSAPI, domain string) ([]dnsapi.Record, error) {
+	flags := cmd.Flags()
+	param := dnsapi.CreateParameter(domain)
+	param.Name, _ = flags.GetString("name")
+	param.Type, _ = flags.GetString("type")
+	param.Value, _ = flags.GetString("value")
+	line, _ := flags.GetString("line")
+	all, _ := flags.GetBool("all")
+
+	caps := client.Capabilities()
+	if flags.Changed("line") && !caps.Lines {
+		return nil, dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持解析线路，请去掉 --line 参数")
+	}
+	if param.Type != "" {
+		if err := caps.ValidRecordType(param.Type); err != nil {
+			return nil, err
+		}
+	}
+	var lines []dnsapi.Line
+	if line != "" {
+		var err error
+		if lines, err = client.ListLines(ctx, domain); err != nil {
+			return nil, err
+		}
+		l, err := dnsapi.FindLine(lines, line)
+		if err != nil {
+			return nil, fmt.Errorf("%w，可以使用 dnscli record lines %s 查看支持的线路", err, domain)
+		}
+		line = l.Code
+	}
+
+	records, err := client.ListRecords(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	// 服务商的查询可能是模糊匹配，这里按条件精确过滤
+	matched := make([]dnsapi.Record, 0, len(records))
+	for _, r := range records {
+		if param.Name != "" && !strings.EqualFold(r.Name, param.Name) {
+			continue
+		}
+		if param.Type != "" && !strings.EqualFold(r.Type, param.Type) {
+			continue
+		}
+		if param.Value != "" && r.Value != param.Value {
+			continue
+		}
+		if line != "" && !onLine(lines, r, line) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+
+	switch {
+	case len(matched) == 0:
+		return nil, dnsapi.Errorf(dnsapi.ErrNotFound, "没有匹配的解析记录")
+	case len(matched) > 1 && !all:
+		printTable(os.Stderr, matched, excludeFields(hiddenFields(caps)))
+		return nil, dnsapi.Errorf(dnsapi.ErrInvalidInput, "匹配到 %d 条解析记录，请使用更精确的条件或指定 --all", len(matched))
+	}
+	fmt.Fprintf(os.Stderr, "匹配到 %d 条解析记录:\n", len(matched))
+	printTable(os.Stderr, matched, excludeFields(hiddenFields(caps)))
+	return matched, nil
+}
+
+// applyRecordFlags 使用命令行中指定的 --ttl、--remark、--weight、--proxied 参数覆盖 param，未指定的保持原值
+func applyRecordFlags(cmd *cobra.Command, param *dnsapi.Parameter) {
+	flags := cmd.Flags()
+	if flags.Changed("ttl") {
+		param.TTL, _ = flags.GetInt("ttl")
+	}
+	if flags.Changed("remark") {
+		param.Remark, _ = flags.GetString("remark")
+	}
+	if flags.Changed("weight") {
+		param.Weight, _ = flags.GetInt("weight")
+		param.ZeroWeight = param.Weight == 0
+	}
+	if flags.Changed("proxied") {
+		param.Proxied, _ = flags.GetBool("proxied")
+	}
+}
+
+// updateRecord 使用命令行中指定的 --ttl、--remark、--weight、--proxied 等参数覆盖 param 后修改解析记录
+func updateRecord(ctx context.Context, cmd *cobra.Command, client dnsapi.DNSAPI, param *dnsapi.Parameter) error {
+	applyRecordFlags(cmd, param)
+	if err := setRecordData(cmd, param); err != nil {
+		return err
+	}
+	if err := client.UpdateRecord(ctx, param); err != nil {
+		return err
+	}
+	printDone("updated %s ok\n", param.ID)
+	return nil
+}
+
+// listRecordSet 返回与 param 主机记录、记录类型相同的解析记录，param 指定了线路时只返回该线路的记录
+func listRecordSet(ctx context.Context, client dnsapi.DNSAPI, param *dnsapi.Parameter) ([]dnsapi.Record, error) {
+	query := dnsapi.CreateParameter(param.Domain)
+	query.Name = param.Name
+	query.Type = param.Type
+	records, err := client.ListRecords(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var lines []dnsapi.Line
+	if param.Line != "" {
+		if lines, err = client.ListLines(ctx, param.Domain); err != nil {
+			return nil, err
+		}
+	}
+	set := make([]dnsapi.Record, 0, len(records))
+	for _, r := range records {
+		if !strings.EqualFold(r.Name, param.Name) || !strings.EqualFold(r.Type, param.Type) {
+			continue
+		}
+		if param.Line != "" && !onLine(lines, r, param.Line) {
+			continue
+		}
+		set = append(set, r)
+	}
+	return set, nil
+}
+
+// onLine 判断记录是否属于线路代码为 code 的线路，记录的线路可能是线路代码或线路名
+func onLine(lines []dnsapi.Line, r dnsapi.Record, code string) bool {
+	l, err := dnsapi.FindLine(lines, r.Line)
+	return err == nil && l.Code == code
+}
+
+// checkFlags 检查命令行参数是否被当前 DNS 服务商支持，避免参数被静默忽略
+func checkFlags(cmd *cobra.Command, caps dnsapi.Capabilities) error {
+	flags := cmd.Flags()
+	if flags.Changed("line") && !caps.Lines {
+		return dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持解析线路，请去掉 --line 参数")
+	}
+	if flags.Changed("proxied") && !caps.Proxied {
+		return dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持代理，请去掉 --proxied 参数")
+	}
+	if flags.Changed("remark") && !caps.Remark {
+		return dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持记录备注，请去掉 --remark 参数")
+	}
+	if flags.Changed("weight") {
+		if !caps.Weight {
+			return dnsapi.Errorf(dnsapi.ErrUnsupported, "当前 DNS 服务商不支持记录权重，请去掉 --weight 参数")
+		}
+		weight, _ := flags.GetInt("weight")
+		if err := caps.ValidWeight(weight); err != nil {
+			return err
+		}
+	}
+	if flags.Changed("ttl") {
+		ttl, _ := flags.GetInt("ttl")
+		if err := caps.ValidTTL(ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hiddenFields 返回 record list 中当前 DNS 服务商不支持而需要隐藏的列
+func hiddenFields(caps dnsapi.Capabilities) []string {
+	var fields []string
+	if !caps.Lines {
+		fields = append(fields, "Line")
+	}
+	if !caps.Priority {
+		fields = append(fields, "Priority")
+	}
+	if !caps.Weight {
+		fields = append(fields, "Weight")
+	}
+	if !caps.Proxied {
+		fields = append(fields, "Proxied")
+	}
+	if !caps.Status {
+		fields = append(fields, "Status")
+	}
+	if !caps.Remark {
+		fields = append(fields, "Remark")
+	}
+	if !caps.Updated {
+		fields = append(fields, "Updated")
+	}
+	return fields
+}
+
+// recordSortNames record list --sort-by 支持的排序字段
+var recordSortNames = []string{"name", "type", "value", "ttl", "updated"}
+
+// recordSortKeys 排序字段对应的比较函数
+var recordSortKeys = map[string]func(a, b dnsapi.Record) int{
+	"name":    func(a, b dnsapi.Record) int { return util.CompareNatural(a.Name, b.Name) },
+	"type":    func(a, b dnsapi.Record) int { return strings.Compare(a.Type, b.Type) },
+	"value":   func(a, b dnsapi.Record) int { return util.CompareAddr(a.Value, b.Value) },
+	"ttl":     func(a, b dnsapi.Record) int { return cmp.Compare(a.TTL, b.TTL) },
+	"updated": func(a, b dnsapi.Record) int { return strings.Compare(a.Updated, b.Updated) },
+}
+
+// sortRecords 按排序字段排序解析记录，相同的保持服务商返回的顺序，key 为空时不排序
+func sortRecords(records []dnsapi.Record, key string) {
+	if compare, ok := recordSortKeys[key]; ok {
+		slices.SortStableFunc(records, compare)
+	}
+}
+
+// recordColumnNames 返回 --columns 可用的列名，即 dnsapi.Record 在表格中输出的字段的 json 标签名
+func recordColumnNames() []string {
+	var names []string
+	for _, f := range reflect.VisibleFields(reflect.TypeFor[dnsapi.Record]()) {
+		if f.Tag.Get("table") != "-" {
+			names = append(names, strings.Split(f.Tag.Get("json"), ",")[0])
+		}
+	}
+	return names
+}
+
+// recordColumns 将 --columns 中的列名转换为 dnsapi.Record 的字段名，列名不区分大小写
+func recordColumns(columns []string) ([]string, error) {
+	all := reflect.VisibleFields(reflect.TypeFor[dnsapi.Record]())
+	fields := make([]string, 0, len(columns))
+	for _, column := range columns {
+		i := slices.IndexFunc(all, func(f reflect.StructField) bool {
+			return f.Tag.Get("table") != "-" && strings.EqualFold(strings.Split(f.Tag.Get("json"), ",")[0], column)
+		})
+		if i < 0 {
+			return nil, dnsapi.Errorf(dnsapi.ErrInvalidInput, "不支持的列：%s，取值为 (%s)", column, strings.Join(recordColumnNames(), ", "))
+		}
+		fields = append(fields, all[i].Name)
+	}
+	return fields, nil
+}
+
+// filterByRemark 返回备注中包含 remark 的解析记录
+func filterByRemark(records []dnsapi.Record, remark string) []dnsapi.Record {
+	filtered := make([]dnsapi.Record, 0, len(records))
+	for _, r := range records {
+		if strings.Contains(r.Remark, remark) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// weightShare record weights 中的一行
+type weightShare struct {
+	ID     string `json:"id" table:"记录ID"`
+	Type   string `json:"type" table:"记录类型"`
+	Line   string `json:"line,omitempty" table:"线路名"`
+	Value  string `json:"value" table:"记录值"`
+	Status string `json:"status,omitempty" table:"状态"`
+	Weight int    `json:"weight" table:"权重"`
+	Share  string `json:"share" table:"占比"`
+}
+
+// weightShares 按记录类型和线路分组计算每条记录的流量占比，暂停的记录占比为 0。
+// 同组启用的记录都没有设置权重时平均分配，否则按权重比例分配，权重为 0 或没有设置权重的记录占比为 0
+func weightShares(records []dnsapi.Record) []weightShare {
+	type group struct{ typ, line string }
+	total := make(map[group]int)
+	count := make(map[group]int)
+	for _, r := range records {
+		if r.Status == dnsapi.StatusDisable {
+			continue
+		}
+		g := group{r.Type, r.Line}
+		total[g] += r.Weight
+		count[g]++
+	}
+
+	shares := make([]weightShare, 0, len(records))
+	for _, r := range records {
+		g := group{r.Type, r.Line}
+		share := 0.0
+		switch {
+		case r.Status == dnsapi.StatusDisable:
+		case total[g] > 0:
+			share = float64(r.Weight) / float64(total[g])
+		default:
+			share = 1 / float64(count[g])
+		}
+		shares = append(shares, weightShare{
+			ID:     r.ID,
+			Type:   r.Type,
+			Line:   r.Line,
+			Value:  r.Value,
+			Status: r.Status,
+			Weight: r.Weight,
+			Share:  fmt.Sprintf("%.1f%%", share*100),
+		})
+	}
+	return shares
 }
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/local"
+	"github.com/spf13/cobra"
+)
+
+func TestRecordColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		want    []string
+		wantErr bool
+	}{
+		{"按指定顺序", []string{"value", "name", "ttl"}, []string{"Value", "Name", "TTL"}, false},
+		{"不区分大小写", []string{"ID", "Updated"}, []string{"ID", "Updated"}, false},
+		{"未知的列", []string{"name", "bogus"}, nil, true},
+		{"不在表格中输出的列", []string{"srv"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := recordColumns(tt.columns)
+			if tt.wantErr {
+				if !errors.Is(err, dnsapi.ErrInvalidInput) {
+					t.Errorf("错误 = %v, 应为 ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("字段 = %v, 应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortRecords(t *testing.T) {
+	records := []dnsapi.Record{
+		{ID: "1", Name: "web10", Type: "A", Value: "10.0.0.10", TTL: 600},
+		{ID: "2", Name: "web2", Type: "AAAA", Value: "2001:db8::1", TTL: 60},
+		{ID: "3", Name: "@", Type: "CNAME", Value: "a.example.com", TTL: 600},
+		{ID: "4", Name: "web2", Type: "A", Value: "10.0.0.2", TTL: 300},
+	}
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"", []string{"1", "2", "3", "4"}},
+		{"name", []string{"3", "2", "4", "1"}},
+		{"type", []string{"1", "4", "2", "3"}},
+		{"value", []string{"4", "1", "2", "3"}},
+		{"ttl", []string{"2", "4", "1", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			sorted := slices.Clone(records)
+			sortRecords(sorted, tt.key)
+			var got []string
+			for _, r := range sorted {
+				got = append(got, r.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("排序结果 = %v, 应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintTableSelectFields(t *testing.T) {
+	records := []dnsapi.Record{{ID: "1", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600}}
+	var buf bytes.Buffer
+	printTable(&buf, records, selectFields([]string{"Value", "Name"}))
+	header := strings.SplitN(buf.String(), "\n", 3)[1]
+	if !strings.Contains(header, "记录值") || strings.Contains(header, "记录ID") {
+		t.Errorf("表头 = %q, 应只包含记录值和主机记录", header)
+	}
+	if strings.Index(header, "记录值") > strings.Index(header, "主机记录") {
+		t.Errorf("表头 = %q, 应按指定顺序输出", header)
+	}
+}
+
+func TestWeightShares(t *testing.T) {
+	record := func(id, line string, weight int, status string) dnsapi.Record {
+		return dnsapi.Record{ID: id, Type: "A", Line: line, Weight: weight, Status: status}
+	}
+	tests := []struct {
+		name    string
+		records []dnsapi.Record
+		want    []string
+	}{
+		{"按权重比例分配", []dnsapi.Record{record("1", "", 10, ""), record("2", "", 30, "")}, []string{"25.0%", "75.0%"}},
+		{"都没有权重时平均分配", []dnsapi.Record{record("1", "", 0, ""), record("2", "", 0, ""), record("3", "", 0, "")}, []string{"33.3%", "33.3%", "33.3%"}},
+		{"没有权重的记录不分配流量", []dnsapi.Record{record("1", "", 10, ""), record("2", "", 0, "")}, []string{"100.0%", "0.0%"}},
+		{"暂停的记录不参与分配", []dnsapi.Record{record("1", "", 10, ""), record("2", "", 10, dnsapi.StatusDisable)}, []string{"100.0%", "0.0%"}},
+		{"暂停的记录不影响平均分配", []dnsapi.Record{record("1", "", 0, ""), record("2", "", 0, ""), record("3", "", 0, dnsapi.StatusDisable)}, []string{"50.0%", "50.0%", "0.0%"}},
+		{"按线路分组", []dnsapi.Record{record("1", "default", 10, ""), record("2", "telecom", 10, ""), record("3", "default", 30, "")}, []string{"25.0%", "100.0%", "75.0%"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range weightShares(tt.records) {
+				got = append(got, s.Share)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("占比 = %v, 应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// selectorCommand 返回带有 delete、update 查询条件参数的命令，args 为要设置的参数
+func selectorCommand(t *testing.T, args ...string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("line", "", "")
+	cmd.Flags().StringP("name", "n", "", "")
+	cmd.Flags().StringP("type", "t", "", "")
+	cmd.Flags().StringP("value", "v", "", "")
+	cmd.Flags().Bool("all", false, "")
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestHasSelectors(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		includeLine bool
+		want        bool
+	}{
+		{"没有查询条件", nil, true, false},
+		{"主机记录", []string{"--name", "www"}, false, true},
+		{"--all", []string{"--all"}, false, true},
+		{"delete 中线路是查询条件", []string{"--line", "default"}, true, true},
+		{"update 中线路不单独作为查询条件", []string{"--line", "default"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasSelectors(selectorCommand(t, tt.args...), tt.includeLine); got != tt.want {
+				t.Errorf("hasSelectors = %v, 应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectRecords(t *testing.T) {
+	ctx := context.Background()
+	client, err := local.NewClient(filepath.Join(t.TempDir(), "local.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AddDomain(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range [][3]string{{"www", "A", "192.0.2.1"}, {"www", "A", "192.0.2.2"}, {"www2", "A", "192.0.2.1"}, {"mail", "MX", "mx.example.com"}} {
+		param := dnsapi.CreateParameter("example.com")
+		param.Name, param.Type, param.Value = r[0], r[1], r[2]
+		if r[1] == "MX" {
+			param.Priority = 10
+		}
+		if err := client.AddRecord(ctx, param); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr error
+	}{
+		{"精确匹配主机记录和记录值", []string{"--name", "www", "--value", "192.0.2.1"}, []string{"www 192.0.2.1"}, nil},
+		{"按记录值匹配多条时需要 --all", []string{"--value", "192.0.2.1"}, nil, dnsapi.ErrInvalidInput},
+		{"匹配多条时需要 --all", []string{"--name", "www"}, nil, dnsapi.ErrInvalidInput},
+		{"指定 --all 时返回所有匹配", []string{"--name", "www", "--all"}, []string{"www 192.0.2.1", "www 192.0.2.2"}, nil},
+		{"没有匹配的记录", []string{"--name", "ftp"}, nil, dnsapi.ErrNotFound},
+		{"记录类型不匹配", []string{"--name", "www", "--type", "AAAA"}, nil, dnsapi.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := selectRecords(ctx, selectorCommand(t, tt.args...), client, "example.com")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("错误 = %v, 应为 %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range records {
+				got = append(got, r.Name+" "+r.Value)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("匹配的记录 = %v, 应为 %v", got, tt.want)
+			}
+		})
+	}
+}
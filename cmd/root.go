@@ -1,31 +1,38 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/liwanggui/dnscli-go/config"
 	"github.com/liwanggui/dnscli-go/dnsapi"
-	"github.com/liwanggui/dnscli-go/dnsapi/aliyun"
-	"github.com/liwanggui/dnscli-go/dnsapi/cloudflare"
-	"github.com/liwanggui/dnscli-go/dnsapi/tencent"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	configName string
-	secretID   string
-	secretKey  string
-	apiToken   string
-	apiKey     string
-	apiEmail   string
 	cfgFile    string
+	timeout    time.Duration
+	retries    int
+	verbose    bool
+	readOnly   bool
+	dryRun     bool
+	output     string
 	rootCmd    = &cobra.Command{
 		Use:   "dnscli",
 		Short: "DNS 记录管理工具",
 		Long:  `DNS 记录管理工具, 支持多个DNS服务商`,
+
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return validOutput(output)
+		},
 	}
 )
 
@@ -34,11 +41,15 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "配置文件路径 (default: $HOME/.dnscli/config.yaml)")
 	rootCmd.PersistentFlags().StringVarP(&configName, "config-name", "N", "", "使用的 DNS 服务商配置名，用于区分多个不同的配置")
-	rootCmd.PersistentFlags().StringVarP(&secretID, "secret-id", "", "", "DNS 服务商 API 密钥 ID")
-	rootCmd.PersistentFlags().StringVarP(&secretKey, "secret-key", "", "", "DNS 服务商 API 密钥 Secret")
-	rootCmd.PersistentFlags().StringVarP(&apiToken, "api-token", "", "", "DNS 服务商 API Token")
-	rootCmd.PersistentFlags().StringVarP(&apiKey, "api-key", "", "", "DNS 服务商 API KEY")
-	rootCmd.PersistentFlags().StringVarP(&apiEmail, "api-email", "", "", "注册 DNS 服务商平台的邮箱地址")
+	addCredentialFlags(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", time.Minute, "单条命令的超时时间，0 表示不限制")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", dnsapi.DefaultRetries,
+		"被限流或遇到临时错误时的最大重试次数，0 表示不重试，未指定时使用配置中的 retries")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "输出每次 API 调用的耗时和结果，命令结束时输出调用统计")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "只校验参数并输出将要执行的修改及修改前后的值，不实际修改")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", outputTable,
+		fmt.Sprintf("查询结果的输出格式，取值为 (%s)，template= 后为 Go 模板，字段名取自 JSON，如 template={{.name}}", strings.Join(outputFormats, ", ")))
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "只读模式，拒绝所有修改操作，也可以在配置中设置 read_only")
 
 	rootCmd.AddCommand(cCmd)
 	rootCmd.AddCommand(rCmd)
@@ -53,32 +64,34 @@ func createProvider() (dnsapi.DNSAPI, error) {
 	configName = getCurrentConfigName()
 	providerType := config.GetConfigType(configName)
 
-	if secretID == "" {
-		secretID = viper.GetString(fmt.Sprintf("configs.%s.credentials.secret_id", configName))
-	}
-	if secretKey == "" {
-		secretKey = viper.GetString(fmt.Sprintf("configs.%s.credentials.secret_key", configName))
-	}
-	if apiToken == "" {
-		apiToken = viper.GetString(fmt.Sprintf("configs.%s.credentials.api_token", configName))
-	}
-	if apiKey == "" {
-		apiKey = viper.GetString(fmt.Sprintf("configs.%s.credentials.api_key", configName))
+	credentials := config.GetCredentials(configName)
+	for key, value := range credentialFlagValues() {
+		credentials[key] = value
 	}
-	if apiEmail == "" {
-		apiEmail = viper.GetString(fmt.Sprintf("configs.%s.credentials.api_email", configName))
+
+	api, err := dnsapi.New(providerType, dnsapi.Config{
+		Credentials: credentials,
+		Endpoint:    config.GetEndpoint(configName),
+		Region:      config.GetRegion(configName),
+	})
+	if err != nil {
+		return nil, err
 	}
+	return dnsapi.Chain(api, middlewares()...), nil
+}
 
-	switch providerType {
-	case "aliyun":
-		return aliyun.NewClient(secretID, secretKey)
-	case "tencent":
-		return tencent.NewClient(secretID, secretKey)
-	case "cloudflare":
-		return cloudflare.NewClient(apiToken, apiEmail, apiKey)
-	default:
-		return nil, fmt.Errorf("不支持的 DNS 服务提供商: %s", providerType)
+// commandContext 返回当前命令使用的 context
+//
+// 该 context 在收到中断信号时取消，并受 --timeout 参数限制。
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
 	}
+	return context.WithCancel(ctx)
 }
 
 func getCurrentConfigName() string {
@@ -94,7 +107,7 @@ func initConfig() {
 	} else {
 		// Find home directory.
 		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
+		checkErr(err)
 
 		viper.AddConfigPath(fmt.Sprintf("%s%s.dnscli", home, string(os.PathSeparator)))
 		viper.AddConfigPath(".")
@@ -109,7 +122,7 @@ func initConfig() {
 	notFound := &viper.ConfigFileNotFoundError{}
 	switch {
 	case err != nil && !errors.As(err, notFound):
-		cobra.CheckErr(err)
+		checkErr(err)
 	case err != nil && errors.As(err, notFound):
 		// The config file is optional, we shouldn't exit when the config is not found
 		break
@@ -119,5 +132,10 @@ func initConfig() {
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err := rootCmd.ExecuteContext(ctx)
+	// 命令失败时同样输出调用统计，便于排查失败的原因
+	printAPIStats()
+	return err
 }
@@ -5,15 +5,13 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/liwanggui/dnscli-go/dnsapi"
 	"github.com/liwanggui/dnscli-go/util"
 	"github.com/spf13/viper"
 )
 
 const DefaultItemName = "default"
 
-// DnsServiceList 支持的 DNS 服务商列表
-var DnsServiceList = []string{"aliyun", "tencent", "cloudflare"}
-
 func IsConfigFileUsed() error {
 	if viper.ConfigFileUsed() == "" {
 		return fmt.Errorf("config file not exist")
@@ -39,46 +37,88 @@ func GetDefaultConfigName() string {
 	return viper.GetString(DefaultItemName)
 }
 
-func AddConfig(name, pType, secretID, secretKey, apiToken, apiEmail, apiKey string, isDefault bool) error {
+// GetCredentials 返回指定配置的认证信息
+func GetCredentials(name string) map[string]string {
+	return viper.GetStringMapString(fmt.Sprintf("configs.%s.credentials", name))
+}
+
+// GetEndpoint 返回指定配置的 API 访问地址，未配置时返回空字符串
+func GetEndpoint(name string) string {
+	return viper.GetString(fmt.Sprintf("configs.%s.endpoint", name))
+}
+
+// GetRegion 返回指定配置的地域，未配置时返回空字符串
+func GetRegion(name string) string {
+	return viper.GetString(fmt.Sprintf("configs.%s.region", name))
+}
+
+// GetRetryConfig 返回指定配置的重试设置，读取 retries、retry_delay 和 retry_max_delay，
+// 未配置的项使用默认值
+func GetRetryConfig(name string) dnsapi.RetryConfig {
+	cfg := dnsapi.RetryConfig{
+		Retries:   dnsapi.DefaultRetries,
+		BaseDelay: viper.GetDuration(fmt.Sprintf("configs.%s.retry_delay", name)),
+		MaxDelay:  viper.GetDuration(fmt.Sprintf("configs.%s.retry_max_delay", name)),
+	}
+	if key := fmt.Sprintf("configs.%s.retries", name); viper.IsSet(key) {
+		cfg.Retries = viper.GetInt(key)
+	}
+	return cfg
+}
+
+// IsReadOnly 判断指定配置是否设置了只读模式
+func IsReadOnly(name string) bool {
+	return viper.GetBool(fmt.Sprintf("configs.%s.read_only", name))
+}
+
+// AddConfig 添加服务商配置，credentials 中未提供的认证信息会提示用户输入，
+// endpoint 和 region 为空时使用服务商的默认值
+func AddConfig(name, pType string, credentials map[string]string, endpoint, region string, isDefault bool) error {
 	if err := ValidConfigName(name); err != nil {
 		name = util.String("请输入配置名", ValidConfigName)
 	}
 
 	if err := ValidProviderType(pType); err != nil {
-		pType, _ = util.Select("请选择服务商", DnsServiceList, 0)
+		providers := dnsapi.Providers()
+		options := make([]string, 0, len(providers))
+		for _, p := range providers {
+			options = append(options, p.String())
+		}
+		_, i := util.Select("请选择服务商", options, 0)
+		pType = providers[i].Name
 	}
 
-	viper.Set(fmt.Sprintf("configs.%s.type", name), pType)
-
-	switch pType {
-	case "cloudflare":
-		if apiToken == "" {
-			apiToken = util.Password("请输入 Cloudflare API Token", nil)
-		}
+	provider, err := dnsapi.LookupProvider(pType)
+	if err != nil {
+		return err
+	}
 
-		if err := ValidEmail(apiEmail); err != nil {
-			apiEmail = util.String("请输入注册 Cloudflare 平台的邮箱地址", ValidEmail)
+	values := make(map[string]string, len(provider.Credentials))
+	for _, field := range provider.Credentials {
+		value := credentials[field.Key]
+		if value == "" {
+			value = promptCredential(field)
 		}
-
-		if apiToken == "" && apiKey == "" {
-			apiKey = util.Password("请输入 Cloudflare API Key", util.ValidStringNotEmpty)
-		} else if apiKey == "" {
-			apiKey = util.Password("请输入 Cloudflare API Key", nil)
+		values[field.Key] = value
+	}
+	if err := provider.ValidCredentials(values); err != nil {
+		return err
+	}
+	if endpoint != "" {
+		if _, _, err := dnsapi.ParseEndpoint(endpoint); err != nil {
+			return err
 		}
+	}
 
-		viper.Set(fmt.Sprintf("configs.%s.credentials.api_token", name), apiToken)
-		viper.Set(fmt.Sprintf("configs.%s.credentials.api_key", name), apiKey)
-		viper.Set(fmt.Sprintf("configs.%s.credentials.api_email", name), apiEmail)
-
-	default:
-		if secretID == "" {
-			secretID = util.Password("请输入 DNS 服务商 API 密钥 ID", util.ValidStringNotEmpty)
-		}
-		if secretKey == "" {
-			secretKey = util.Password("请输入 DNS 服务商 API 密钥 Secret", util.ValidStringNotEmpty)
-		}
-		viper.Set(fmt.Sprintf("configs.%s.credentials.secret_id", name), secretID)
-		viper.Set(fmt.Sprintf("configs.%s.credentials.secret_key", name), secretKey)
+	viper.Set(fmt.Sprintf("configs.%s.type", name), pType)
+	for key, value := range values {
+		viper.Set(fmt.Sprintf("configs.%s.credentials.%s", name, key), value)
+	}
+	if endpoint != "" {
+		viper.Set(fmt.Sprintf("configs.%s.endpoint", name), endpoint)
+	}
+	if region != "" {
+		viper.Set(fmt.Sprintf("configs.%s.region", name), region)
 	}
 
 	if isDefault || !viper.IsSet(DefaultItemName) {
@@ -88,6 +128,31 @@ func AddConfig(name, pType, secretID, secretKey, apiToken, apiEmail, apiKey stri
 	return WriteConfig()
 }
 
+// promptCredential 提示用户输入一项认证信息
+func promptCredential(field dnsapi.CredentialField) string {
+	valid := func(s string) error {
+		if s == "" {
+			if field.Required {
+				return util.ValidStringNotEmpty(s)
+			}
+			return nil
+		}
+		if field.Validate != nil {
+			return field.Validate(s)
+		}
+		return nil
+	}
+
+	message := "请输入 " + field.Description
+	if !field.Required {
+		message += " (可选)"
+	}
+	if field.Secret {
+		return util.Password(message, valid)
+	}
+	return util.String(message, valid)
+}
+
 func SetDefaultConfig(name string) error {
 	defaultName := GetDefaultConfigName()
 	if name == defaultName {
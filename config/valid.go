@@ -2,8 +2,8 @@ package config
 
 import (
 	"fmt"
-	"regexp"
 
+	"github.com/liwanggui/dnscli-go/dnsapi"
 	"github.com/spf13/viper"
 )
 
@@ -24,20 +24,8 @@ func ValidConfigName(name string) error {
 
 // ValidProviderType 判断服务商是否支持
 func ValidProviderType(providerType string) error {
-	for _, p := range DnsServiceList {
-		if p == providerType {
-			return nil
-		}
-	}
-	return fmt.Errorf("暂不支持此 DNS 服务商：%s", providerType)
-}
-
-var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-
-// ValidEmail 判断邮箱地址是否合法
-func ValidEmail(email string) error {
-	if emailRegex.MatchString(email) {
-		return nil
+	if _, err := dnsapi.LookupProvider(providerType); err != nil {
+		return fmt.Errorf("暂不支持此 DNS 服务商：%s", providerType)
 	}
-	return fmt.Errorf("无效的邮箱地址：%s", email)
+	return nil
 }
@@ -1,15 +1,32 @@
 package aliyun
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
 	"github.com/liwanggui/dnscli-go/dnsapi"
 	"strings"
+	"time"
 )
 
-var regionID = "cn-hangzhou"
+// defaultRegion 未配置地域时使用的地域
+const defaultRegion = "cn-hangzhou"
+
+func init() {
+	dnsapi.Register(&dnsapi.Provider{
+		Name:        "aliyun",
+		Description: "阿里云云解析 DNS",
+		Credentials: []dnsapi.CredentialField{
+			{Key: "secret_id", Description: "阿里云 AccessKey ID", Secret: true, Required: true},
+			{Key: "secret_key", Description: "阿里云 AccessKey Secret", Secret: true, Required: true},
+		},
+		New: func(cfg dnsapi.Config) (dnsapi.DNSAPI, error) {
+			return NewClient(cfg.Get("secret_id"), cfg.Get("secret_key"), cfg.Region, cfg.Endpoint)
+		},
+	})
+}
 
 // Client 实现阿里云DNS服务提供商
 type Client struct {
@@ -17,9 +34,14 @@ type Client struct {
 }
 
 // NewClient 创建新的阿里云DNS提供商实例
-func NewClient(accessKeyID, accessKeySecret string) (*Client, error) {
+//
+// region 为空时使用 cn-hangzhou，endpoint 为空时根据地域使用阿里云的公网地址。
+func NewClient(accessKeyID, accessKeySecret, region, endpoint string) (*Client, error) {
+	if region == "" {
+		region = defaultRegion
+	}
 	client, err := alidns.NewClientWithAccessKey(
-		regionID,
+		region,
 		accessKeyID,
 		accessKeySecret,
 	)
@@ -27,13 +49,30 @@ func NewClient(accessKeyID, accessKeySecret string) (*Client, error) {
 		return nil, fmt.Errorf("创建阿里云DNS客户端失败: %v", err)
 	}
 
-	return &Client{api: client}, nil
+	c := &Client{api: client}
+	if endpoint != "" {
+		if err := c.SetEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// SetEndpoint 设置 API 的访问地址，默认使用阿里云的公网地址
+func (client *Client) SetEndpoint(endpoint string) error {
+	scheme, host, err := dnsapi.ParseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	client.api.Domain = host
+	client.api.GetConfig().WithScheme(scheme)
+	return nil
 }
 
 // ListRecords 获取指定域名的所有解析记录
-func (client *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error) {
+func (client *Client) ListRecords(ctx context.Context, param *dnsapi.Parameter) ([]dnsapi.Record, error) {
 	if param.Domain == "" {
-		return nil, fmt.Errorf("域名不能为空")
+		return nil, dnsapi.Errorf(dnsapi.ErrInvalidInput, "域名不能为空")
 	}
 
 	request := alidns.CreateDescribeDomainRecordsRequest()
@@ -49,33 +88,21 @@ func (client *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, err
 
 	for {
 		request.PageNumber = requests.NewInteger(pageNumber)
-		response, err := client.api.DescribeDomainRecords(request)
+		response, err := call(ctx, func() (*alidns.DescribeDomainRecordsResponse, error) {
+			return client.api.DescribeDomainRecords(request)
+		})
 		if err != nil {
-			// 尝试提供更详细的错误信息
-			if strings.Contains(err.Error(), "InvalidDomainName") {
-				return nil, fmt.Errorf("无效的域名格式: %s", param.Domain)
-			} else if strings.Contains(err.Error(), "DomainNotFound") {
-				return nil, fmt.Errorf("域名不存在或未在阿里云DNS中管理: %s", param.Domain)
-			} else if strings.Contains(err.Error(), "InvalidAccessKeyId.NotFound") {
-				return nil, fmt.Errorf("无效的AccessKey ID或Secret")
-			}
-			return nil, fmt.Errorf("获取域名记录失败: %v", err)
+			return nil, fmt.Errorf("获取域名记录失败: %w", wrapError(err))
 		}
 
+		updated := updateTimes(response.GetHttpContentBytes())
 		for _, r := range response.DomainRecords.Record {
-			records = append(records, dnsapi.Record{
-				ID:       r.RecordId,
-				Domain:   param.Domain,
-				Name:     r.RR,
-				Type:     r.Type,
-				Value:    r.Value,
-				TTL:      int(r.TTL),
-				Line:     r.Line,
-				Priority: int(r.Priority),
-			})
+			record := convertRecord(param.Domain, r)
+			record.Updated = updated[r.RecordId]
+			records = append(records, record)
 		}
 
-		if int64(len(records)) >= response.TotalCount {
+		if len(response.DomainRecords.Record) == 0 || int64(len(records)) >= response.TotalCount {
 			break
 		}
 
@@ -86,104 +113,401 @@ func (client *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, err
 }
 
 // GetRecord 获取特定记录的详情
-func (client *Client) GetRecord(param *dnsapi.Parameter) (*dnsapi.Record, error) {
+func (client *Client) GetRecord(ctx context.Context, param *dnsapi.Parameter) (*dnsapi.Record, error) {
 	request := alidns.CreateDescribeDomainRecordInfoRequest()
 	if param.ID == "" {
-		return nil, fmt.Errorf("记录 ID 不能为空")
+		return nil, dnsapi.Errorf(dnsapi.ErrInvalidInput, "记录 ID 不能为空")
 	}
 	request.RecordId = param.ID
-	response, err := client.api.DescribeDomainRecordInfo(request)
+	response, err := call(ctx, func() (*alidns.DescribeDomainRecordInfoResponse, error) {
+		return client.api.DescribeDomainRecordInfo(request)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取记录详情失败: %w", wrapError(err))
+	}
+
+	// 记录详情接口不返回权重，从记录列表中查找同一条记录以返回与 ListRecords 相同的字段
+	list := dnsapi.CreateParameter(response.DomainName)
+	list.Name = response.RR
+	list.Type = response.Type
+	records, err := client.ListRecords(ctx, list)
 	if err != nil {
-		return nil, fmt.Errorf("获取记录详情失败: %v", err)
+		return nil, err
+	}
+	for _, r := range records {
+		if r.ID == response.RecordId {
+			return &r, nil
+		}
 	}
 
-	return &dnsapi.Record{
-		ID:       response.RecordId,
-		Domain:   param.Domain,
-		Name:     response.RR,
+	record := convertRecord(response.DomainName, alidns.Record{
+		RecordId: response.RecordId,
+		RR:       response.RR,
 		Type:     response.Type,
 		Value:    response.Value,
-		TTL:      int(response.TTL),
-		Priority: int(response.Priority),
-	}, nil
+		TTL:      response.TTL,
+		Line:     response.Line,
+		Priority: response.Priority,
+		Status:   response.Status,
+		Remark:   response.Remark,
+	})
+	return &record, nil
+}
+
+// convertRecord 将阿里云的解析记录转换为 dnsapi.Record
+func convertRecord(domain string, r alidns.Record) dnsapi.Record {
+	record := dnsapi.Record{
+		ID:       r.RecordId,
+		Domain:   domain,
+		Name:     r.RR,
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      int(r.TTL),
+		Line:     r.Line,
+		Priority: int(r.Priority),
+		Weight:   r.Weight,
+		Status:   strings.ToUpper(r.Status),
+		Remark:   r.Remark,
+	}
+	record.SetData()
+	return record
+}
+
+// updateTimes 从记录列表的原始响应中解析记录的更新时间，SDK 的 alidns.Record 中没有 UpdateTimestamp 字段
+func updateTimes(content []byte) map[string]string {
+	var body struct {
+		DomainRecords struct {
+			Record []struct {
+				RecordId        string
+				UpdateTimestamp int64
+			}
+		}
+	}
+	if err := json.Unmarshal(content, &body); err != nil {
+		return nil
+	}
+	times := make(map[string]string, len(body.DomainRecords.Record))
+	for _, r := range body.DomainRecords.Record {
+		if r.UpdateTimestamp > 0 {
+			times[r.RecordId] = time.UnixMilli(r.UpdateTimestamp).Format(time.DateTime)
+		}
+	}
+	return times
 }
 
 // AddRecord 添加新的解析记录
-func (client *Client) AddRecord(param *dnsapi.Parameter) error {
+func (client *Client) AddRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	if err := param.ParseData(); err != nil {
+		return err
+	}
 	request := alidns.CreateAddDomainRecordRequest()
 	request.DomainName = param.Domain
 	request.RR = param.Name
 	request.Type = param.Type
 	request.Value = param.Value
+	request.Line = param.Line
 	if param.TTL != 0 {
 		request.TTL = requests.NewInteger(param.TTL)
 	}
-	if param.Priority > 0 {
+	// 阿里云的 SRV、CAA 记录值即为完整的记录值，优先级参数仅用于 MX 记录
+	if param.Type == "MX" {
 		request.Priority = requests.NewInteger(param.Priority)
 	}
 
-	_, err := client.api.AddDomainRecord(request)
+	response, err := call(ctx, func() (*alidns.AddDomainRecordResponse, error) {
+		return client.api.AddDomainRecord(request)
+	})
 	if err != nil {
-		return fmt.Errorf("添加解析记录失败: %v", err)
+		return fmt.Errorf("添加解析记录失败: %w", wrapError(err))
 	}
+	param.ID = response.RecordId
 
+	// 添加记录的接口不支持备注和权重，需要单独设置
+	if param.Remark != "" {
+		if err := client.updateRemark(ctx, response.RecordId, param.Remark); err != nil {
+			return err
+		}
+	}
+	if param.Weight > 0 {
+		return client.updateWeight(ctx, param, response.RecordId)
+	}
 	return nil
 }
 
 // UpdateRecord 更新现有解析记录
-func (client *Client) UpdateRecord(param *dnsapi.Parameter) error {
+func (client *Client) UpdateRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	if err := param.ParseData(); err != nil {
+		return err
+	}
+	// 不指定线路时接口会把线路改为默认，这里保留记录原来的线路
+	line := param.Line
+	if line == "" {
+		record, err := client.GetRecord(ctx, param)
+		if err != nil {
+			return err
+		}
+		line = record.Line
+	}
+
 	request := alidns.CreateUpdateDomainRecordRequest()
 	request.RecordId = param.ID
 	request.RR = param.Name
 	request.Type = param.Type
 	request.Value = param.Value
+	request.Line = line
 	if param.TTL != 0 {
 		request.TTL = requests.NewInteger(param.TTL)
 	}
-	if param.Priority > 0 {
+	// 阿里云的 SRV、CAA 记录值即为完整的记录值，优先级参数仅用于 MX 记录
+	if param.Type == "MX" {
 		request.Priority = requests.NewInteger(param.Priority)
 	}
 
-	_, err := client.api.UpdateDomainRecord(request)
+	_, err := call(ctx, func() (*alidns.UpdateDomainRecordResponse, error) {
+		return client.api.UpdateDomainRecord(request)
+	})
+	if err != nil {
+		return fmt.Errorf("更新解析记录失败: %w", wrapError(err))
+	}
+
+	if param.Remark != "" {
+		if err := client.updateRemark(ctx, param.ID, param.Remark); err != nil {
+			return err
+		}
+	}
+	if param.Weight > 0 {
+		return client.updateWeight(ctx, param, param.ID)
+	}
+	return nil
+}
+
+// updateRemark 设置解析记录的备注
+func (client *Client) updateRemark(ctx context.Context, recordID, remark string) error {
+	request := alidns.CreateUpdateDomainRecordRemarkRequest()
+	request.RecordId = recordID
+	request.Remark = remark
+	_, err := call(ctx, func() (*alidns.UpdateDomainRecordRemarkResponse, error) {
+		return client.api.UpdateDomainRecordRemark(request)
+	})
+	if err != nil {
+		return fmt.Errorf("设置解析记录备注失败: %w", wrapError(err))
+	}
+	return nil
+}
+
+// updateWeight 开启解析记录所在主机记录的权重配置，并设置记录的权重
+// https://help.aliyun.com/zh/dns/weight-configuration
+func (client *Client) updateWeight(ctx context.Context, param *dnsapi.Parameter, recordID string) error {
+	statusRequest := alidns.CreateSetDNSSLBStatusRequest()
+	statusRequest.DomainName = param.Domain
+	statusRequest.SubDomain = subDomain(param.Name, param.Domain)
+	statusRequest.Type = param.Type
+	statusRequest.Line = param.Line
+	statusRequest.Open = requests.NewBoolean(true)
+	_, err := call(ctx, func() (*alidns.SetDNSSLBStatusResponse, error) {
+		return client.api.SetDNSSLBStatus(statusRequest)
+	})
 	if err != nil {
-		return fmt.Errorf("更新解析记录失败: %v", err)
+		return fmt.Errorf("开启权重配置失败: %w", wrapError(err))
 	}
 
+	request := alidns.CreateUpdateDNSSLBWeightRequest()
+	request.RecordId = recordID
+	request.Weight = requests.NewInteger(param.Weight)
+	_, err = call(ctx, func() (*alidns.UpdateDNSSLBWeightResponse, error) {
+		return client.api.UpdateDNSSLBWeight(request)
+	})
+	if err != nil {
+		return fmt.Errorf("设置解析记录权重失败: %w", wrapError(err))
+	}
 	return nil
 }
 
+// subDomain 返回主机记录对应的完整域名
+func subDomain(name, domain string) string {
+	if name == "" || name == "@" {
+		return domain
+	}
+	return name + "." + domain
+}
+
 // DeleteRecord 删除解析记录
-func (client *Client) DeleteRecord(param *dnsapi.Parameter) error {
+func (client *Client) DeleteRecord(ctx context.Context, param *dnsapi.Parameter) error {
 	request := alidns.CreateDeleteDomainRecordRequest()
 	if param.ID == "" {
-		return fmt.Errorf("记录 ID 不能为空")
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "记录 ID 不能为空")
 	}
 	request.RecordId = param.ID
-	_, err := client.api.DeleteDomainRecord(request)
+	_, err := call(ctx, func() (*alidns.DeleteDomainRecordResponse, error) {
+		return client.api.DeleteDomainRecord(request)
+	})
 	if err != nil {
-		if err, ok := err.(errors.Error); ok {
-			return fmt.Errorf("删除解析记录失败: %v", err.Message())
-		} else {
-			return fmt.Errorf("删除解析记录失败: %v", err)
-		}
+		return fmt.Errorf("删除解析记录失败: %w", wrapError(err))
+	}
+	return nil
+}
+
+// SetRecordStatus 启用或暂停解析记录
+func (client *Client) SetRecordStatus(ctx context.Context, param *dnsapi.Parameter) error {
+	if param.ID == "" {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "记录 ID 不能为空")
+	}
+	if err := dnsapi.ValidStatus(param.Status); err != nil {
+		return err
+	}
+
+	request := alidns.CreateSetDomainRecordStatusRequest()
+	request.RecordId = param.ID
+	// 阿里云的状态取值为 Enable 和 Disable
+	request.Status = "Enable"
+	if param.Status == dnsapi.StatusDisable {
+		request.Status = "Disable"
+	}
+	_, err := call(ctx, func() (*alidns.SetDomainRecordStatusResponse, error) {
+		return client.api.SetDomainRecordStatus(request)
+	})
+	if err != nil {
+		return fmt.Errorf("设置解析记录状态失败: %w", wrapError(err))
 	}
 	return nil
 }
 
+// ListLines 列出域名支持的解析线路
+func (client *Client) ListLines(ctx context.Context, domain string) ([]dnsapi.Line, error) {
+	request := alidns.CreateDescribeSupportLinesRequest()
+	request.DomainName = domain
+	response, err := call(ctx, func() (*alidns.DescribeSupportLinesResponse, error) {
+		return client.api.DescribeSupportLines(request)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取解析线路失败: %w", wrapError(err))
+	}
+
+	lines := make([]dnsapi.Line, 0, len(response.RecordLines.RecordLine))
+	for _, l := range response.RecordLines.RecordLine {
+		lines = append(lines, dnsapi.Line{
+			Code:   l.LineCode,
+			Name:   l.LineDisplayName,
+			Parent: l.FatherCode,
+		})
+	}
+	return lines, nil
+}
+
 // ListDomains 列出账号下所有域名
-func (client *Client) ListDomains() ([]string, error) {
+func (client *Client) ListDomains(ctx context.Context) ([]dnsapi.Domain, error) {
 	request := alidns.CreateDescribeDomainsRequest()
+	request.PageSize = requests.NewInteger(100)
+
+	pageNumber := 1
+	domains := make([]dnsapi.Domain, 0, 20)
+
+	for {
+		request.PageNumber = requests.NewInteger(pageNumber)
+		response, err := call(ctx, func() (*alidns.DescribeDomainsResponse, error) {
+			return client.api.DescribeDomains(request)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取域名列表失败: %w", wrapError(err))
+		}
 
-	response, err := client.api.DescribeDomains(request)
+		for _, d := range response.Domains.Domain {
+			status := "normal"
+			if d.InstanceExpired {
+				status = "expired"
+			}
+			domains = append(domains, dnsapi.Domain{
+				ID:          d.DomainId,
+				Name:        d.DomainName,
+				Status:      status,
+				RecordCount: int(d.RecordCount),
+				Plan:        d.VersionName,
+				NameServers: d.DnsServers.DnsServer,
+				Created:     time.UnixMilli(d.CreateTimestamp).Format(time.DateTime),
+			})
+		}
+
+		if len(response.Domains.Domain) == 0 || int64(len(domains)) >= response.TotalCount {
+			break
+		}
+
+		pageNumber++
+	}
+
+	return domains, nil
+}
+
+// AddDomain 添加域名
+func (client *Client) AddDomain(ctx context.Context, domain string) (*dnsapi.Domain, error) {
+	request := alidns.CreateAddDomainRequest()
+	request.DomainName = domain
+	response, err := call(ctx, func() (*alidns.AddDomainResponse, error) {
+		return client.api.AddDomain(request)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("添加域名失败: %w", wrapError(err))
+	}
+
+	return &dnsapi.Domain{
+		ID:          response.DomainId,
+		Name:        response.DomainName,
+		NameServers: response.DnsServers.DnsServer,
+	}, nil
+}
+
+// DeleteDomain 删除域名
+func (client *Client) DeleteDomain(ctx context.Context, domain string) error {
+	request := alidns.CreateDeleteDomainRequest()
+	request.DomainName = domain
+	_, err := call(ctx, func() (*alidns.DeleteDomainResponse, error) {
+		return client.api.DeleteDomain(request)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("获取域名列表失败: %v", err)
+		return fmt.Errorf("删除域名失败: %w", wrapError(err))
+	}
+	return nil
+}
+
+// Capabilities 返回阿里云DNS支持的功能
+func (client *Client) Capabilities() dnsapi.Capabilities {
+	return dnsapi.Capabilities{
+		Lines:       true,
+		Priority:    true,
+		Weight:      true,
+		MinWeight:   1,
+		MaxWeight:   100,
+		Remark:      true,
+		Status:      true,
+		Updated:     true,
+		MinTTL:      600,
+		RecordTypes: dnsapi.RecordTypes,
 	}
+}
 
-	domains := make([]string, 0, len(response.Domains.Domain))
-	for _, d := range response.Domains.Domain {
-		domains = append(domains, d.DomainName)
-		//fmt.Println(d.DomainName, !d.InstanceExpired, time.UnixMilli(d.CreateTimestamp).Format("2006-01-02 15:04:05"))
+// call 在 ctx 的控制下执行 SDK 请求
+//
+// 阿里云 SDK 不支持 context，这里在独立的 goroutine 中发起请求，
+// ctx 被取消或超时后立即返回，不再等待请求结束。
+func call[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
 	}
 
-	return domains, nil
+	type result struct {
+		response T
+		err      error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		response, err := fn()
+		ch <- result{response: response, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case r := <-ch:
+		return r.response, r.err
+	}
 }
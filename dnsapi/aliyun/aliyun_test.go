@@ -0,0 +1,77 @@
+package aliyun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/dnsapitest"
+	"github.com/liwanggui/dnscli-go/dnsapi/fakeapi"
+)
+
+func TestConformance(t *testing.T) {
+	dnsapitest.Run(t, dnsapitest.Config{
+		Domain: "example.com",
+		New: func(t *testing.T) dnsapi.DNSAPI {
+			srv := fakeapi.NewAliyun(t)
+			if _, err := srv.Backend.AddDomain(context.Background(), "example.com"); err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClient("id", "secret", "", srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return client
+		},
+	})
+}
+
+func TestListRecordsEmptyPage(t *testing.T) {
+	// 总数大于实际返回的记录数时，遇到空页应停止翻页
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"RequestId":"1","TotalCount":5,"PageNumber":1,"PageSize":100,"DomainRecords":{"Record":[]}}`)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient("id", "secret", "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	records, err := client.ListRecords(ctx, dnsapi.CreateParameter("example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Errorf("返回 %d 条记录, 应为 0", len(records))
+	}
+}
+
+func TestListRecordsErrorMessage(t *testing.T) {
+	// 参数错误保留服务商的错误信息，只在前面加上操作名称
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"RequestId":"1","Code":"InvalidType","Message":"The specified type is invalid."}`)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient("id", "secret", "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := dnsapi.CreateParameter("example.com")
+	param.Type = "BOGUS"
+	_, err = client.ListRecords(context.Background(), param)
+	if !errors.Is(err, dnsapi.ErrInvalidInput) {
+		t.Fatalf("错误 = %v, 应为 ErrInvalidInput", err)
+	}
+	if want := "获取域名记录失败: The specified type is invalid. (InvalidType)"; err.Error() != want {
+		t.Errorf("错误信息 = %q, 应为 %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,67 @@
+package aliyun
+
+import (
+	"net/http"
+	"strings"
+
+	sdkerrors "github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// wrapError 将阿里云 SDK 返回的错误转换为 dnsapi.Error
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	e, ok := err.(sdkerrors.Error)
+	if !ok {
+		return err
+	}
+	kind := classify(e.ErrorCode())
+	if kind == nil {
+		kind = dnsapi.KindFromHTTPStatus(e.HttpStatus())
+	}
+	wrapped := dnsapi.NewError(kind, e.ErrorCode(), e.Message(), err)
+	if se, ok := e.(*sdkerrors.ServerError); ok {
+		wrapped.RetryAfter = dnsapi.ParseRetryAfter(http.Header(se.RespHeaders).Get("Retry-After"))
+	}
+	return wrapped
+}
+
+// classify 根据阿里云错误码判断错误分类
+// https://api.aliyun.com/document/Alidns/2015-01-09/errorCode
+func classify(code string) error {
+	switch {
+	case code == "":
+		return nil
+	case strings.HasPrefix(code, "InvalidAccessKeyId"),
+		strings.HasPrefix(code, "SignatureDoesNotMatch"),
+		strings.HasPrefix(code, "InvalidSecurityToken"),
+		strings.HasPrefix(code, "Forbidden"),
+		strings.HasPrefix(code, "NoPermission"):
+		return dnsapi.ErrAuthFailed
+	case strings.HasPrefix(code, "Throttling"):
+		return dnsapi.ErrRateLimited
+	case strings.HasPrefix(code, "ServiceUnavailable"),
+		strings.HasPrefix(code, "InternalError"),
+		code == "SDK.TimeoutError":
+		return dnsapi.ErrUnavailable
+	case strings.Contains(code, "NotFound"),
+		strings.Contains(code, "NoExist"),
+		strings.Contains(code, "NotExist"),
+		code == "IncorrectDomainUser",
+		code == "DomainRecordNotBelongToUser":
+		return dnsapi.ErrNotFound
+	case strings.Contains(code, "Duplicate"),
+		strings.Contains(code, "Conflict"),
+		strings.Contains(code, "Exist"):
+		return dnsapi.ErrConflict
+	case strings.Contains(code, "Quota"),
+		strings.Contains(code, "LimitExceeded"):
+		return dnsapi.ErrQuotaExceeded
+	case strings.HasPrefix(code, "Invalid"),
+		strings.HasPrefix(code, "MissingParameter"):
+		return dnsapi.ErrInvalidInput
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package aliyun
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	sdkerrors "github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		status int
+		code   string
+		want   error
+	}{
+		{http.StatusForbidden, "InvalidAccessKeyId.NotFound", dnsapi.ErrAuthFailed},
+		{http.StatusBadRequest, "SignatureDoesNotMatch", dnsapi.ErrAuthFailed},
+		{http.StatusForbidden, "Forbidden.RAM", dnsapi.ErrAuthFailed},
+		{http.StatusForbidden, "NoPermission", dnsapi.ErrAuthFailed},
+		{http.StatusBadRequest, "Throttling.User", dnsapi.ErrRateLimited},
+		{http.StatusServiceUnavailable, "ServiceUnavailable", dnsapi.ErrUnavailable},
+		{http.StatusInternalServerError, "InternalError", dnsapi.ErrUnavailable},
+		{http.StatusBadRequest, "InvalidDomainName.NoExist", dnsapi.ErrNotFound},
+		{http.StatusBadRequest, "DomainRecordNotBelongToUser", dnsapi.ErrNotFound},
+		{http.StatusBadRequest, "IncorrectDomainUser", dnsapi.ErrNotFound},
+		{http.StatusBadRequest, "DomainRecordDuplicate", dnsapi.ErrConflict},
+		{http.StatusBadRequest, "Record.Conflict", dnsapi.ErrConflict},
+		{http.StatusBadRequest, "QuotaExceeded.Record", dnsapi.ErrQuotaExceeded},
+		{http.StatusBadRequest, "LimitExceeded.Domain", dnsapi.ErrQuotaExceeded},
+		{http.StatusBadRequest, "InvalidRR.Format", dnsapi.ErrInvalidInput},
+		{http.StatusBadRequest, "MissingParameter", dnsapi.ErrInvalidInput},
+		// 未知的错误码按 HTTP 状态码分类
+		{http.StatusNotFound, "Unknown", dnsapi.ErrNotFound},
+		{http.StatusTooManyRequests, "Unknown", dnsapi.ErrRateLimited},
+		{http.StatusBadGateway, "Unknown", dnsapi.ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			body := fmt.Sprintf(`{"Code":%q,"Message":"message"}`, tt.code)
+			err := wrapError(sdkerrors.NewServerError(tt.status, body, ""))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("wrapError(%d %s) = %v, 应为 %v", tt.status, tt.code, err, tt.want)
+			}
+			var e *dnsapi.Error
+			if !errors.As(err, &e) || e.Code != tt.code || e.Message != "message" {
+				t.Errorf("wrapError(%d %s) = %#v, 应保留错误码和错误信息", tt.status, tt.code, err)
+			}
+		})
+	}
+
+	if kind := classify("UnknownCode"); kind != nil {
+		t.Errorf("classify(UnknownCode) = %v, 应为 nil", kind)
+	}
+	plain := errors.New("plain")
+	if err := wrapError(plain); err != plain {
+		t.Errorf("非 SDK 错误应原样返回, 得到 %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package dnsapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangeAction 批量修改中的操作类型
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+// Change 批量修改中的一项，Param 与 AddRecord、UpdateRecord、DeleteRecord 的参数相同
+type Change struct {
+	Action ChangeAction
+	Param  *Parameter
+}
+
+// ChangeResult 批量修改中一项的执行结果
+type ChangeResult struct {
+	Change Change
+	// Err 执行失败的原因，成功时为 nil
+	Err error
+}
+
+// Batcher 支持批量修改解析记录的服务商可以实现此接口，通过 ApplyChanges 调用
+type Batcher interface {
+	// ApplyChanges 批量修改 domain 下的解析记录，返回与 changes 一一对应的执行结果，
+	// 返回 error 表示整个请求失败，此时所有修改都没有执行
+	ApplyChanges(ctx context.Context, domain string, changes []Change) ([]ChangeResult, error)
+}
+
+// ApplyChanges 批量修改 domain 下的解析记录，服务商实现了 Batcher 时使用服务商的批量接口，
+// 否则逐项调用 AddRecord、UpdateRecord 和 DeleteRecord
+func ApplyChanges(ctx context.Context, api DNSAPI, domain string, changes []Change) ([]ChangeResult, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if b, ok := api.(Batcher); ok {
+		return b.ApplyChanges(ctx, domain, changes)
+	}
+	return ApplySequential(ctx, api, domain, changes), nil
+}
+
+// ApplySequential 逐项执行修改，单项失败不影响后续修改，ctx 被取消后剩余的修改不再执行
+func ApplySequential(ctx context.Context, api DNSAPI, domain string, changes []Change) []ChangeResult {
+	results := make([]ChangeResult, 0, len(changes))
+	for _, c := range changes {
+		if err := ctx.Err(); err != nil {
+			results = append(results, ChangeResult{Change: c, Err: err})
+			continue
+		}
+		results = append(results, ChangeResult{Change: c, Err: ApplyChange(ctx, api, domain, c)})
+	}
+	return results
+}
+
+// ApplyChange 执行单项修改
+func ApplyChange(ctx context.Context, api DNSAPI, domain string, c Change) error {
+	param := *c.Param
+	param.Domain = domain
+	switch c.Action {
+	case ChangeCreate:
+		return api.AddRecord(ctx, &param)
+	case ChangeUpdate:
+		return api.UpdateRecord(ctx, &param)
+	case ChangeDelete:
+		return api.DeleteRecord(ctx, &param)
+	}
+	return Errorf(ErrInvalidInput, "无效的修改操作：%s", c.Action)
+}
+
+// ChangeResults 返回所有修改都使用同一结果的执行结果，用于整批成功或失败的批量接口
+func ChangeResults(changes []Change, err error) []ChangeResult {
+	results := make([]ChangeResult, 0, len(changes))
+	for _, c := range changes {
+		results = append(results, ChangeResult{Change: c, Err: err})
+	}
+	return results
+}
+
+// String 返回修改的简要说明，如 `delete 123456`、`create www A 1.1.1.1`
+func (c Change) String() string {
+	p := c.Param
+	if c.Action == ChangeDelete {
+		return fmt.Sprintf("%s %s", c.Action, p.ID)
+	}
+	if c.Action == ChangeUpdate {
+		return fmt.Sprintf("%s %s %s %s %s", c.Action, p.ID, p.Name, p.Type, p.Value)
+	}
+	return fmt.Sprintf("%s %s %s %s", c.Action, p.Name, p.Type, p.Value)
+}
@@ -0,0 +1,65 @@
+package dnsapi
+
+import "slices"
+
+// Capabilities 描述服务商支持的功能，命令行根据它决定显示的列和允许的参数
+type Capabilities struct {
+	// Lines 是否支持解析线路
+	Lines bool
+	// Proxied 是否支持代理（CDN 加速）
+	Proxied bool
+	// Priority 是否支持 MX/SRV 记录优先级
+	Priority bool
+	// Weight 是否支持记录权重
+	Weight bool
+	// Remark 是否支持记录备注
+	Remark bool
+	// Status 是否支持启用/暂停记录
+	Status bool
+	// Updated 是否返回记录的更新时间
+	Updated bool
+	// MinTTL 支持的最小 TTL 值
+	MinTTL int
+	// AutoTTL 表示由服务商自动设置 TTL 的特殊值，不受 MinTTL 限制，0 表示不支持
+	AutoTTL int
+	// MinWeight、MaxWeight 记录权重的取值范围，MinWeight 为 0 时允许将权重设置为 0
+	MinWeight int
+	MaxWeight int
+	// RecordTypes 支持的记录类型
+	RecordTypes []string
+}
+
+// ValidTTL 判断 TTL 是否被服务商支持
+func (c Capabilities) ValidTTL(ttl int) error {
+	switch {
+	case c.AutoTTL != 0 && ttl == c.AutoTTL:
+		return nil
+	case ttl >= c.MinTTL:
+		return nil
+	case c.AutoTTL != 0:
+		return Errorf(ErrInvalidInput, "当前 DNS 服务商支持的最小 TTL 为 %d，或者使用 %d 表示自动", c.MinTTL, c.AutoTTL)
+	}
+	return Errorf(ErrInvalidInput, "当前 DNS 服务商支持的最小 TTL 为 %d", c.MinTTL)
+}
+
+// ValidWeight 判断记录权重是否被服务商支持
+func (c Capabilities) ValidWeight(weight int) error {
+	if !c.Weight {
+		return Errorf(ErrUnsupported, "当前 DNS 服务商不支持记录权重")
+	}
+	if weight < c.MinWeight || weight > c.MaxWeight {
+		return Errorf(ErrInvalidInput, "记录权重的取值范围为 %d-%d", c.MinWeight, c.MaxWeight)
+	}
+	return nil
+}
+
+// ValidRecordType 判断记录类型是否被服务商支持
+func (c Capabilities) ValidRecordType(rType string) error {
+	if slices.Contains(c.RecordTypes, rType) {
+		return nil
+	}
+	if err := ValidRecordType(rType); err != nil {
+		return err
+	}
+	return Errorf(ErrUnsupported, "当前 DNS 服务商不支持此记录类型：%s", rType)
+}
@@ -0,0 +1,65 @@
+package dnsapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidTTL(t *testing.T) {
+	fixed := Capabilities{MinTTL: 600}
+	auto := Capabilities{MinTTL: 60, AutoTTL: 1}
+	tests := []struct {
+		name string
+		caps Capabilities
+		ttl  int
+		ok   bool
+	}{
+		{"最小值", fixed, 600, true},
+		{"大于最小值", fixed, 3600, true},
+		{"小于最小值", fixed, 599, false},
+		{"不支持自动", fixed, 1, false},
+		{"自动", auto, 1, true},
+		{"小于最小值且不是自动", auto, 2, false},
+		{"最小值以下", auto, 59, false},
+		{"支持自动时的最小值", auto, 60, true},
+		{"零", auto, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.caps.ValidTTL(tt.ttl)
+			if tt.ok && err != nil {
+				t.Errorf("ValidTTL(%d) = %v, 应为 nil", tt.ttl, err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ValidTTL(%d) = %v, 应为 ErrInvalidInput", tt.ttl, err)
+			}
+		})
+	}
+}
+
+func TestValidWeight(t *testing.T) {
+	aliyun := Capabilities{Weight: true, MinWeight: 1, MaxWeight: 100}
+	dnspod := Capabilities{Weight: true, MinWeight: 0, MaxWeight: 100}
+	tests := []struct {
+		name   string
+		caps   Capabilities
+		weight int
+		err    error
+	}{
+		{"最小值", aliyun, 1, nil},
+		{"最大值", aliyun, 100, nil},
+		{"不支持 0", aliyun, 0, ErrInvalidInput},
+		{"支持 0", dnspod, 0, nil},
+		{"超过最大值", dnspod, 101, ErrInvalidInput},
+		{"负数", dnspod, -1, ErrInvalidInput},
+		{"不支持权重", Capabilities{}, 10, ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.caps.ValidWeight(tt.weight)
+			if tt.err == nil && err != nil || tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("ValidWeight(%d) = %v, 应为 %v", tt.weight, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/dns"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// batchSize 单次批量请求的最大修改数，免费套餐的上限为 200
+const batchSize = 200
+
+// ApplyChanges 使用批量接口修改解析记录，返回与 changes 一一对应的执行结果
+//
+// Cloudflare 的批量修改是原子的，同一个请求中的修改要么全部成功，要么全部失败。
+// 修改数超过 batchSize 时按删除、更新、添加的顺序拆分为多个请求，每个请求的失败只影响该请求中的修改。
+// https://developers.cloudflare.com/api/resources/dns/subresources/records/methods/batch/
+func (this *Client) ApplyChanges(ctx context.Context, domain string, changes []dnsapi.Change) ([]dnsapi.ChangeResult, error) {
+	results := dnsapi.ChangeResults(changes, nil)
+	zoneID, err := this.getZoneID(ctx, domain)
+	if err != nil {
+		return dnsapi.ChangeResults(changes, err), nil
+	}
+
+	// 按接口的执行顺序排列：删除、更新、添加
+	var pending []int
+	for _, action := range []dnsapi.ChangeAction{dnsapi.ChangeDelete, dnsapi.ChangeUpdate, dnsapi.ChangeCreate} {
+		for i, c := range changes {
+			if c.Action == action {
+				pending = append(pending, i)
+			}
+		}
+	}
+	for i, c := range changes {
+		if c.Action != dnsapi.ChangeDelete && c.Action != dnsapi.ChangeUpdate && c.Action != dnsapi.ChangeCreate {
+			results[i].Err = dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的修改操作：%s", c.Action)
+		}
+	}
+
+	for chunk := range slices.Chunk(pending, batchSize) {
+		this.applyBatch(ctx, zoneID, domain, results, chunk)
+	}
+	return results, nil
+}
+
+// applyBatch 使用一个批量请求执行 results 中下标为 indexes 的修改，并设置每项修改的执行结果
+func (this *Client) applyBatch(ctx context.Context, zoneID, domain string, results []dnsapi.ChangeResult, indexes []int) {
+	var (
+		deletes []dns.RecordBatchParamsDelete
+		puts    []dns.BatchPutUnionParam
+		posts   []dns.RecordUnionParam
+		sent    []int
+	)
+	for _, i := range indexes {
+		c := results[i].Change
+		param := *c.Param
+		param.Domain = domain
+		switch c.Action {
+		case dnsapi.ChangeDelete:
+			deletes = append(deletes, dns.RecordBatchParamsDelete{ID: cloudflare.F(param.ID)})
+		case dnsapi.ChangeCreate:
+			record, err := getRecordUnionParam(&param)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			posts = append(posts, record)
+		case dnsapi.ChangeUpdate:
+			p, err := this.keepRemark(ctx, &param)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			record, err := getRecordUnionParam(p)
+			if err == nil {
+				var put dns.BatchPutUnionParam
+				if put, err = batchPutParam(param.ID, record); err == nil {
+					puts = append(puts, put)
+				}
+			}
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+		}
+		sent = append(sent, i)
+	}
+	if len(sent) == 0 {
+		return
+	}
+
+	params := dns.RecordBatchParams{ZoneID: cloudflare.F(zoneID)}
+	if len(deletes) > 0 {
+		params.Deletes = cloudflare.F(deletes)
+	}
+	if len(puts) > 0 {
+		params.Puts = cloudflare.F(puts)
+	}
+	if len(posts) > 0 {
+		params.Posts = cloudflare.F(posts)
+	}
+	if _, err := this.client.DNS.Records.Batch(ctx, params); err != nil {
+		err = fmt.Errorf("批量修改解析记录失败: %w", wrapError(err))
+		for _, i := range sent {
+			results[i].Err = err
+		}
+	}
+}
+
+// batchPutParam 将记录参数转换为批量接口中覆盖记录的参数
+func batchPutParam(id string, record dns.RecordUnionParam) (dns.BatchPutUnionParam, error) {
+	recordID := cloudflare.F(id)
+	switch r := record.(type) {
+	case dns.ARecordParam:
+		return dns.BatchPutAParam{ID: recordID, ARecordParam: r}, nil
+	case dns.AAAARecordParam:
+		return dns.BatchPutAAAAParam{ID: recordID, AAAARecordParam: r}, nil
+	case dns.CNAMERecordParam:
+		return dns.BatchPutCNAMEParam{ID: recordID, CNAMERecordParam: r}, nil
+	case dns.MXRecordParam:
+		return dns.BatchPutMXParam{ID: recordID, MXRecordParam: r}, nil
+	case dns.TXTRecordParam:
+		return dns.BatchPutTXTParam{ID: recordID, TXTRecordParam: r}, nil
+	case dns.NSRecordParam:
+		return dns.BatchPutNSParam{ID: recordID, NSRecordParam: r}, nil
+	case dns.PTRRecordParam:
+		return dns.BatchPutPTRParam{ID: recordID, PTRRecordParam: r}, nil
+	case dns.RecordOpenpgpkeyParam:
+		return dns.BatchPutOpenpgpkeyParam{
+			ID:      recordID,
+			Name:    r.Name,
+			Type:    cloudflare.F(dns.BatchPutOpenpgpkeyTypeOpenpgpkey),
+			Content: r.Content,
+			TTL:     r.TTL,
+			Comment: r.Comment,
+		}, nil
+	case dns.SRVRecordParam:
+		return dns.BatchPutSRVParam{ID: recordID, SRVRecordParam: r}, nil
+	case dns.CAARecordParam:
+		return dns.BatchPutCAAParam{ID: recordID, CAARecordParam: r}, nil
+	case dns.HTTPSRecordParam:
+		return dns.BatchPutHTTPSParam{ID: recordID, HTTPSRecordParam: r}, nil
+	case dns.SVCBRecordParam:
+		return dns.BatchPutSVCBParam{ID: recordID, SVCBRecordParam: r}, nil
+	case dns.URIRecordParam:
+		return dns.BatchPutURIParam{ID: recordID, URIRecordParam: r}, nil
+	case dns.DSRecordParam:
+		return dns.BatchPutDSParam{ID: recordID, DSRecordParam: r}, nil
+	case dns.DNSKEYRecordParam:
+		return dns.BatchPutDNSKEYParam{ID: recordID, DNSKEYRecordParam: r}, nil
+	case dns.CERTRecordParam:
+		return dns.BatchPutCERTParam{ID: recordID, CERTRecordParam: r}, nil
+	case dns.SSHFPRecordParam:
+		return dns.BatchPutSSHFPParam{ID: recordID, SSHFPRecordParam: r}, nil
+	case dns.TLSARecordParam:
+		return dns.BatchPutTLSAParam{ID: recordID, TLSARecordParam: r}, nil
+	case dns.SMIMEARecordParam:
+		return dns.BatchPutSMIMEAParam{ID: recordID, SMIMEARecordParam: r}, nil
+	case dns.NAPTRRecordParam:
+		return dns.BatchPutNAPTRParam{ID: recordID, NAPTRRecordParam: r}, nil
+	}
+	return nil, dnsapi.Errorf(dnsapi.ErrUnsupported, "批量接口不支持此记录类型")
+}
@@ -2,73 +2,164 @@ package cloudflare
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/accounts"
 	"github.com/cloudflare/cloudflare-go/v4/dns"
 	"github.com/cloudflare/cloudflare-go/v4/option"
 	"github.com/cloudflare/cloudflare-go/v4/zones"
 	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/util"
+	"slices"
 	"strings"
 	"time"
 )
 
+// perPage 分页查询解析记录时每页的记录数
+const perPage = 100
+
+// zonesPerPage 分页查询域名时每页的域名数，接口允许的最大值为 50
+const zonesPerPage = 50
+
+func init() {
+	dnsapi.Register(&dnsapi.Provider{
+		Name:        "cloudflare",
+		Description: "Cloudflare DNS",
+		Credentials: []dnsapi.CredentialField{
+			{Key: "api_token", Description: "Cloudflare API Token", Secret: true},
+			{Key: "api_email", Description: "注册 Cloudflare 平台的邮箱地址", Validate: util.ValidEmail},
+			{Key: "api_key", Description: "Cloudflare API Key", Secret: true},
+			{Key: "account_id", Description: "Cloudflare 账户 ID，添加域名时使用，账号下只有一个账户时可不填"},
+		},
+		Validate: func(credentials map[string]string) error {
+			if credentials["api_token"] != "" {
+				return nil
+			}
+			if credentials["api_email"] == "" || credentials["api_key"] == "" {
+				return dnsapi.Errorf(dnsapi.ErrInvalidInput, "cloudflare 需要提供 api_token，或者同时提供 api_email 和 api_key")
+			}
+			return nil
+		},
+		New: func(cfg dnsapi.Config) (dnsapi.DNSAPI, error) {
+			return NewClient(cfg.Get("api_token"), cfg.Get("api_email"), cfg.Get("api_key"), cfg.Get("account_id"), cfg.Endpoint)
+		},
+	})
+}
+
 type Client struct {
-	client *cloudflare.Client
+	client    *cloudflare.Client
+	accountID string
+	// options 创建 client 使用的认证选项，修改 API 地址时需要重新创建 client
+	options []option.RequestOption
 }
 
-func NewClient(apiToken, apiEmail, apiKey string) (*Client, error) {
+// NewClient 创建 Cloudflare 客户端，endpoint 为空时使用 https://api.cloudflare.com/client/v4/
+//
+// Cloudflare 不区分地域，配置中的 region 会被忽略。
+func NewClient(apiToken, apiEmail, apiKey, accountID, endpoint string) (*Client, error) {
+	options := []option.RequestOption{option.WithAPIKey(apiKey), option.WithAPIEmail(apiEmail)}
 	if apiToken != "" {
-		return &Client{client: cloudflare.NewClient(option.WithAPIToken(apiToken))}, nil
+		options = []option.RequestOption{option.WithAPIToken(apiToken)}
 	}
-	return &Client{client: cloudflare.NewClient(option.WithAPIKey(apiKey), option.WithAPIEmail(apiEmail))}, nil
+	// 关闭 SDK 自带的重试，由 dnsapi.Retry 中间件统一处理
+	options = append(options, option.WithMaxRetries(0))
+	c := &Client{client: cloudflare.NewClient(options...), accountID: accountID, options: options}
+	if endpoint != "" {
+		if err := c.SetEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
-func (this *Client) getZoneID(domainName string) (zoneID string, err error) {
-	page, err := this.client.Zones.List(context.TODO(), zones.ZoneListParams{Name: cloudflare.F(domainName)})
+// SetEndpoint 设置 API 的访问地址，默认为 https://api.cloudflare.com/client/v4/
+func (this *Client) SetEndpoint(endpoint string) error {
+	scheme, host, err := dnsapi.ParseEndpoint(endpoint)
 	if err != nil {
-		panic(err.Error())
+		return err
+	}
+	// 只指定主机时使用默认的 API 路径
+	baseURL := strings.ToLower(scheme) + "://" + host + "/client/v4/"
+	if strings.Contains(endpoint, "://") {
+		baseURL = endpoint
+	}
+	this.client = cloudflare.NewClient(append(slices.Clone(this.options), option.WithBaseURL(baseURL))...)
+	return nil
+}
+
+// getAccountID 返回添加域名时使用的账户 ID，未配置时使用账号下唯一的账户
+func (this *Client) getAccountID(ctx context.Context) (string, error) {
+	if this.accountID != "" {
+		return this.accountID, nil
+	}
+	page, err := this.client.Accounts.List(ctx, accounts.AccountListParams{})
+	if err != nil {
+		return "", fmt.Errorf("获取账户列表失败: %w", wrapError(err))
+	}
+	switch len(page.Result) {
+	case 0:
+		return "", dnsapi.Errorf(dnsapi.ErrNotFound, "没有可用的 Cloudflare 账户")
+	case 1:
+		return page.Result[0].ID, nil
+	}
+	return "", dnsapi.Errorf(dnsapi.ErrInvalidInput, "存在多个 Cloudflare 账户，请在配置中指定 account_id")
+}
+
+func (this *Client) getZoneID(ctx context.Context, domainName string) (zoneID string, err error) {
+	page, err := this.client.Zones.List(ctx, zones.ZoneListParams{Name: cloudflare.F(domainName)})
+	if err != nil {
+		return "", fmt.Errorf("获取域名信息失败: %w", wrapError(err))
 	}
 	for _, v := range page.Result {
 		if v.Name == domainName {
 			return v.ID, nil
 		}
 	}
-	return "", fmt.Errorf("zone %s not found", domainName)
+	return "", dnsapi.Errorf(dnsapi.ErrNotFound, "zone %s not found", domainName)
 }
 
-func (this *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error) {
-	zoneID, err := this.getZoneID(param.Domain)
+func (this *Client) ListRecords(ctx context.Context, param *dnsapi.Parameter) ([]dnsapi.Record, error) {
+	zoneID, err := this.getZoneID(ctx, param.Domain)
 	if err != nil {
 		return nil, err
 	}
 	recordListParams := dns.RecordListParams{}
 	recordListParams.ZoneID = cloudflare.F(zoneID)
-	recordListParams.Name = cloudflare.F(dns.RecordListParamsName{Startswith: cloudflare.F(param.Name)})
+	if param.Name == "@" {
+		recordListParams.Name = cloudflare.F(dns.RecordListParamsName{Exact: cloudflare.F(param.Domain)})
+	} else {
+		recordListParams.Name = cloudflare.F(dns.RecordListParamsName{Startswith: cloudflare.F(param.Name)})
+	}
 	recordListParams.Type = cloudflare.F(dns.RecordListParamsType(param.Type))
 	recordListParams.Content = cloudflare.F(dns.RecordListParamsContent{Startswith: cloudflare.F(param.Value)})
-	recordListParams.PerPage = cloudflare.F(2.0)
+	recordListParams.PerPage = cloudflare.F(float64(perPage))
 	records := make([]dnsapi.Record, 0)
 
-	page, err := this.client.DNS.Records.List(context.TODO(), recordListParams)
-	if err != nil {
-		return nil, err
-	}
-	for {
+	// 不使用 GetNextPage，它内部使用 context.Background()，无法被取消
+	for pageNumber := 1; ; pageNumber++ {
+		recordListParams.Page = cloudflare.F(float64(pageNumber))
+		page, err := this.client.DNS.Records.List(ctx, recordListParams)
+		if err != nil {
+			return nil, fmt.Errorf("获取域名记录失败: %w", wrapError(err))
+		}
 		for _, v := range page.Result {
-			records = append(records, dnsapi.Record{
+			record := dnsapi.Record{
 				ID:       v.ID,
 				Domain:   param.Domain,
-				Name:     strings.Replace(v.Name, "."+param.Domain, "", -1),
-				Value:    v.Content,
+				Name:     relativeName(v.Name, param.Domain),
+				Value:    recordValue(v),
 				Type:     string(v.Type),
 				TTL:      int(v.TTL),
 				Priority: int(v.Priority),
 				Proxied:  v.Proxied,
+				Remark:   v.Comment,
 				Updated:  v.ModifiedOn.Format(time.DateTime),
-			})
+			}
+			record.SetData()
+			records = append(records, record)
 		}
-		page, _ = page.GetNextPage()
-		if len(page.Result) == 0 {
+		if len(page.Result) < perPage {
 			break
 		}
 	}
@@ -76,127 +167,197 @@ func (this *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error
 }
 
 // GetRecord 获取特定记录的详情
-func (this *Client) GetRecord(param *dnsapi.Parameter) (*dnsapi.Record, error) {
-	zoneID, err := this.getZoneID(param.Domain)
+func (this *Client) GetRecord(ctx context.Context, param *dnsapi.Parameter) (*dnsapi.Record, error) {
+	zoneID, err := this.getZoneID(ctx, param.Domain)
 	if err != nil {
 		return nil, err
 	}
-	page, err := this.client.DNS.Records.Get(context.TODO(), param.ID, dns.RecordGetParams{ZoneID: cloudflare.F(zoneID)})
+	page, err := this.client.DNS.Records.Get(ctx, param.ID, dns.RecordGetParams{ZoneID: cloudflare.F(zoneID)})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("获取记录详情失败: %w", wrapError(err))
 	}
-	return &dnsapi.Record{
+	record := &dnsapi.Record{
 		ID:       page.ID,
 		Domain:   param.Domain,
-		Name:     page.Name,
-		Value:    page.Content,
+		Name:     relativeName(page.Name, param.Domain),
+		Value:    recordValue(*page),
 		Type:     string(page.Type),
 		TTL:      int(page.TTL),
 		Priority: int(page.Priority),
 		Proxied:  page.Proxied,
+		Remark:   page.Comment,
 		Updated:  page.ModifiedOn.Format(time.DateTime),
-	}, nil
+	}
+	record.SetData()
+	return record, nil
 }
 
 // AddRecord 添加新的解析记录
 // https://developers.cloudflare.com/api/resources/dns/subresources/records/methods/create/
-func (this *Client) AddRecord(param *dnsapi.Parameter) error {
-	zoneID, err := this.getZoneID(param.Domain)
+func (this *Client) AddRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	zoneID, err := this.getZoneID(ctx, param.Domain)
 	if err != nil {
 		return err
 	}
-	recordParam := getRecordUnionParam(param)
-	_, err = this.client.DNS.Records.New(context.TODO(), dns.RecordNewParams{
+	recordParam, err := getRecordUnionParam(param)
+	if err != nil {
+		return err
+	}
+	record, err := this.client.DNS.Records.New(ctx, dns.RecordNewParams{
 		ZoneID: cloudflare.F(zoneID),
-		Record: *recordParam,
+		Record: recordParam,
 	})
-
-	return err
+	if err != nil {
+		return fmt.Errorf("添加解析记录失败: %w", wrapError(err))
+	}
+	param.ID = record.ID
+	return nil
 }
 
-// UpdateRecord 更新现有解析记录
-func (this *Client) UpdateRecord(param *dnsapi.Parameter) error {
-	zoneID, err := this.getZoneID(param.Domain)
+// UpdateRecord 更新现有解析记录，param 中没有备注时保留原来的备注
+func (this *Client) UpdateRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	zoneID, err := this.getZoneID(ctx, param.Domain)
+	if err != nil {
+		return err
+	}
+	param, err = this.keepRemark(ctx, param)
+	if err != nil {
+		return err
+	}
+	recordParam, err := getRecordUnionParam(param)
 	if err != nil {
 		return err
 	}
-	recordParam := getRecordUnionParam(param)
 	recordUpdateParams := dns.RecordUpdateParams{
 		ZoneID: cloudflare.F(zoneID),
-		Record: *recordParam,
+		Record: recordParam,
 	}
-	_, err = this.client.DNS.Records.Update(context.TODO(), param.ID, recordUpdateParams)
-	return err
+	_, err = this.client.DNS.Records.Update(ctx, param.ID, recordUpdateParams)
+	if err != nil {
+		return fmt.Errorf("更新解析记录失败: %w", wrapError(err))
+	}
+	return nil
+}
+
+// keepRemark 更新接口会覆盖整条记录，param 中没有备注时返回带有原备注的参数副本
+func (this *Client) keepRemark(ctx context.Context, param *dnsapi.Parameter) (*dnsapi.Parameter, error) {
+	if param.Remark != "" {
+		return param, nil
+	}
+	record, err := this.GetRecord(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	p := *param
+	p.Remark = record.Remark
+	return &p, nil
 }
 
 // DeleteRecord 删除解析记录
-func (this *Client) DeleteRecord(param *dnsapi.Parameter) error {
-	zoneID, err := this.getZoneID(param.Domain)
+func (this *Client) DeleteRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	zoneID, err := this.getZoneID(ctx, param.Domain)
 	if err != nil {
 		return err
 	}
-	_, err = this.client.DNS.Records.Delete(context.TODO(), param.ID,
+	_, err = this.client.DNS.Records.Delete(ctx, param.ID,
 		dns.RecordDeleteParams{ZoneID: cloudflare.F(zoneID)})
-	return err
+	if err != nil {
+		return fmt.Errorf("删除解析记录失败: %w", wrapError(err))
+	}
+	return nil
+}
+
+// SetRecordStatus Cloudflare 不支持暂停单条解析记录
+func (this *Client) SetRecordStatus(ctx context.Context, param *dnsapi.Parameter) error {
+	return dnsapi.Errorf(dnsapi.ErrUnsupported, "Cloudflare 不支持启用/暂停解析记录")
+}
+
+// ListLines Cloudflare 不支持解析线路
+func (this *Client) ListLines(ctx context.Context, domain string) ([]dnsapi.Line, error) {
+	return nil, dnsapi.Errorf(dnsapi.ErrUnsupported, "Cloudflare 不支持解析线路")
 }
 
 // ListDomains 列出账号下所有域名
-func (this *Client) ListDomains() ([]string, error) {
-	page, err := this.client.Zones.List(context.TODO(), zones.ZoneListParams{})
+func (this *Client) ListDomains(ctx context.Context) ([]dnsapi.Domain, error) {
+	params := zones.ZoneListParams{PerPage: cloudflare.F(float64(zonesPerPage))}
+	domains := make([]dnsapi.Domain, 0)
+
+	for pageNumber := 1; ; pageNumber++ {
+		params.Page = cloudflare.F(float64(pageNumber))
+		page, err := this.client.Zones.List(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("获取域名列表失败: %w", wrapError(err))
+		}
+		for _, v := range page.Result {
+			domains = append(domains, newDomain(&v))
+		}
+		if len(page.Result) < zonesPerPage {
+			break
+		}
+	}
+	return domains, nil
+}
+
+// AddDomain 添加域名
+// https://developers.cloudflare.com/api/resources/zones/methods/create/
+func (this *Client) AddDomain(ctx context.Context, name string) (*dnsapi.Domain, error) {
+	accountID, err := this.getAccountID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var zoneList = make([]string, 0)
-	for _, v := range page.Result {
-		zoneList = append(zoneList, v.Name)
+	zone, err := this.client.Zones.New(ctx, zones.ZoneNewParams{
+		Account: cloudflare.F(zones.ZoneNewParamsAccount{ID: cloudflare.F(accountID)}),
+		Name:    cloudflare.F(name),
+		Type:    cloudflare.F(zones.TypeFull),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("添加域名失败: %w", wrapError(err))
 	}
-	return zoneList, nil
+	domain := newDomain(zone)
+	return &domain, nil
 }
 
-func getRecordUnionParam(param *dnsapi.Parameter) *dns.RecordUnionParam {
-	var recordUnionParam dns.RecordUnionParam
-	switch param.Type {
-	case "A":
-		recordUnionParam = dns.ARecordParam{
-			Name:    cloudflare.F(fmt.Sprintf("%s.%s", param.Name, param.Domain)),
-			Type:    cloudflare.F(dns.ARecordTypeA),
-			Content: cloudflare.F(param.Value),
-			TTL:     cloudflare.F(dns.TTL(param.TTL)),
-			Proxied: cloudflare.F(param.Proxied),
-		}
-	case "AAAA":
-		recordUnionParam = dns.AAAARecordParam{
-			Name:    cloudflare.F(fmt.Sprintf("%s.%s", param.Name, param.Domain)),
-			Type:    cloudflare.F(dns.AAAARecordTypeAAAA),
-			Content: cloudflare.F(param.Value),
-			TTL:     cloudflare.F(dns.TTL(param.TTL)),
-			Proxied: cloudflare.F(param.Proxied),
-		}
-	case "CNAME":
-		recordUnionParam = dns.CNAMERecordParam{
-			Name:    cloudflare.F(fmt.Sprintf("%s.%s", param.Name, param.Domain)),
-			Type:    cloudflare.F(dns.CNAMERecordTypeCNAME),
-			Content: cloudflare.F(param.Value),
-			TTL:     cloudflare.F(dns.TTL(param.TTL)),
-			Proxied: cloudflare.F(param.Proxied),
-		}
-	case "MX":
-		recordUnionParam = dns.MXRecordParam{
-			Name:     cloudflare.F(fmt.Sprintf("%s.%s", param.Name, param.Domain)),
-			Type:     cloudflare.F(dns.MXRecordTypeMX),
-			Content:  cloudflare.F(param.Value),
-			TTL:      cloudflare.F(dns.TTL(param.TTL)),
-			Priority: cloudflare.F(float64(param.Priority)),
-			Proxied:  cloudflare.F(param.Proxied),
-		}
-	case "TXT":
-		recordUnionParam = dns.TXTRecordParam{
-			Name:    cloudflare.F(fmt.Sprintf("%s.%s", param.Name, param.Domain)),
-			Type:    cloudflare.F(dns.TXTRecordTypeTXT),
-			Content: cloudflare.F(param.Value),
-			TTL:     cloudflare.F(dns.TTL(param.TTL)),
-			Proxied: cloudflare.F(param.Proxied),
-		}
+// DeleteDomain 删除域名
+func (this *Client) DeleteDomain(ctx context.Context, domain string) error {
+	zoneID, err := this.getZoneID(ctx, domain)
+	if err != nil {
+		return err
+	}
+	_, err = this.client.Zones.Delete(ctx, zones.ZoneDeleteParams{ZoneID: cloudflare.F(zoneID)})
+	if err != nil {
+		return fmt.Errorf("删除域名失败: %w", wrapError(err))
+	}
+	return nil
+}
+
+// Capabilities 返回 Cloudflare 支持的功能
+func (this *Client) Capabilities() dnsapi.Capabilities {
+	return dnsapi.Capabilities{
+		Proxied:     true,
+		Priority:    true,
+		Remark:      true,
+		Updated:     true,
+		MinTTL:      60,
+		AutoTTL:     1,
+		RecordTypes: recordTypes,
+	}
+}
+
+// newDomain 将 Cloudflare zone 转换为 dnsapi.Domain
+func newDomain(zone *zones.Zone) dnsapi.Domain {
+	// SDK 的 Zone 结构中没有套餐字段，从原始 JSON 中读取
+	var plan struct {
+		Name string `json:"name"`
+	}
+	if field, ok := zone.JSON.ExtraFields["plan"]; ok {
+		_ = json.Unmarshal([]byte(field.Raw()), &plan)
+	}
+	return dnsapi.Domain{
+		ID:          zone.ID,
+		Name:        zone.Name,
+		Status:      string(zone.Status),
+		Plan:        plan.Name,
+		NameServers: zone.NameServers,
+		Created:     zone.CreatedOn.Format(time.DateTime),
 	}
-	return &recordUnionParam
 }
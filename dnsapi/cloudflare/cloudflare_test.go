@@ -0,0 +1,90 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/dnsapitest"
+	"github.com/liwanggui/dnscli-go/dnsapi/fakeapi"
+)
+
+func TestConformance(t *testing.T) {
+	dnsapitest.Run(t, dnsapitest.Config{
+		Domain: "example.com",
+		New: func(t *testing.T) dnsapi.DNSAPI {
+			return newTestClient(t)
+		},
+	})
+}
+
+// newTestClient 返回连接到模拟 API 的客户端，模拟 API 中已经添加了域名 example.com
+func newTestClient(t *testing.T) *Client {
+	srv := fakeapi.NewCloudflare(t)
+	if _, err := srv.Backend.AddDomain(context.Background(), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient("token", "", "", "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestApplyChangesChunks(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	changes := make([]dnsapi.Change, 0, batchSize+50)
+	for i := range cap(changes) {
+		param := &dnsapi.Parameter{Name: fmt.Sprintf("chunk%d", i), Type: "A", Value: "192.0.2.1"}
+		changes = append(changes, dnsapi.Change{Action: dnsapi.ChangeCreate, Param: param})
+	}
+	results, err := client.ApplyChanges(ctx, "example.com", changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatalf("%s: %v", r.Change, r.Err)
+		}
+	}
+	records, err := client.ListRecords(ctx, dnsapi.CreateParameter("example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(changes) {
+		t.Errorf("记录数 = %d, 应为 %d", len(records), len(changes))
+	}
+}
+
+func TestApplyChangesResults(t *testing.T) {
+	client := newTestClient(t)
+	changes := []dnsapi.Change{
+		{Action: dnsapi.ChangeCreate, Param: &dnsapi.Parameter{Name: "www", Type: "A", Value: "192.0.2.1"}},
+		{Action: dnsapi.ChangeDelete, Param: &dnsapi.Parameter{ID: "missing"}},
+		{Action: dnsapi.ChangeCreate, Param: &dnsapi.Parameter{Name: "bad", Type: "BOGUS", Value: "x"}},
+	}
+	results, err := client.ApplyChanges(context.Background(), "example.com", changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(changes) {
+		t.Fatalf("返回 %d 条结果, 应为 %d 条", len(results), len(changes))
+	}
+	// 批量请求是原子的，删除失败时同一请求中的添加也失败，无法转换的修改单独失败
+	for i, r := range results {
+		if r.Err == nil {
+			t.Errorf("%s 应该失败", changes[i])
+		}
+	}
+	if !errors.Is(results[1].Err, dnsapi.ErrNotFound) {
+		t.Errorf("删除不存在的记录的错误 = %v, 应为 ErrNotFound", results[1].Err)
+	}
+
+	results, err = client.ApplyChanges(context.Background(), "missing.com", changes[:1])
+	if err != nil || len(results) != 1 || !errors.Is(results[0].Err, dnsapi.ErrNotFound) {
+		t.Errorf("域名不存在时的结果 = %v, %v, 应为每项修改返回 ErrNotFound", results, err)
+	}
+}
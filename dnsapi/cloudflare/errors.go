@@ -0,0 +1,53 @@
+package cloudflare
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// errorKinds Cloudflare 错误码与错误分类的对应关系
+// https://developers.cloudflare.com/fundamentals/api/troubleshooting/
+var errorKinds = map[int64]error{
+	1061:  dnsapi.ErrConflict,      // zone already exists
+	6003:  dnsapi.ErrAuthFailed,    // invalid request headers
+	7003:  dnsapi.ErrNotFound,      // could not route to ..., object identifier is invalid
+	9103:  dnsapi.ErrAuthFailed,    // unknown X-Auth-Key or X-Auth-Email
+	9106:  dnsapi.ErrAuthFailed,    // missing X-Auth-Key
+	9109:  dnsapi.ErrAuthFailed,    // invalid access token
+	10000: dnsapi.ErrAuthFailed,    // authentication error
+	81044: dnsapi.ErrNotFound,      // record does not exist
+	81045: dnsapi.ErrQuotaExceeded, // record quota exceeded
+	81053: dnsapi.ErrConflict,      // an A, AAAA, or CNAME record with that host already exists
+	81057: dnsapi.ErrConflict,      // the record already exists
+	81058: dnsapi.ErrConflict,      // an identical record already exists
+}
+
+// wrapError 将 Cloudflare SDK 返回的错误转换为 dnsapi.Error
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *cloudflare.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	var code, message string
+	var kind error
+	if len(e.Errors) > 0 {
+		code = strconv.FormatInt(e.Errors[0].Code, 10)
+		message = e.Errors[0].Message
+		kind = errorKinds[e.Errors[0].Code]
+	}
+	if kind == nil {
+		kind = dnsapi.KindFromHTTPStatus(e.StatusCode)
+	}
+	wrapped := dnsapi.NewError(kind, code, message, err)
+	if e.Response != nil {
+		wrapped.RetryAfter = dnsapi.ParseRetryAfter(e.Response.Header.Get("Retry-After"))
+	}
+	return wrapped
+}
@@ -0,0 +1,66 @@
+package cloudflare
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/shared"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		status int
+		code   int64
+		want   error
+	}{
+		{http.StatusBadRequest, 1061, dnsapi.ErrConflict},
+		{http.StatusBadRequest, 6003, dnsapi.ErrAuthFailed},
+		{http.StatusBadRequest, 7003, dnsapi.ErrNotFound},
+		{http.StatusForbidden, 9103, dnsapi.ErrAuthFailed},
+		{http.StatusBadRequest, 9106, dnsapi.ErrAuthFailed},
+		{http.StatusBadRequest, 9109, dnsapi.ErrAuthFailed},
+		{http.StatusForbidden, 10000, dnsapi.ErrAuthFailed},
+		{http.StatusNotFound, 81044, dnsapi.ErrNotFound},
+		{http.StatusBadRequest, 81045, dnsapi.ErrQuotaExceeded},
+		{http.StatusBadRequest, 81053, dnsapi.ErrConflict},
+		{http.StatusBadRequest, 81057, dnsapi.ErrConflict},
+		{http.StatusBadRequest, 81058, dnsapi.ErrConflict},
+		// 未知的错误码按 HTTP 状态码分类
+		{http.StatusBadRequest, 1004, dnsapi.ErrInvalidInput},
+		{http.StatusUnauthorized, 1, dnsapi.ErrAuthFailed},
+		{http.StatusNotFound, 1, dnsapi.ErrNotFound},
+		{http.StatusConflict, 1, dnsapi.ErrConflict},
+		{http.StatusTooManyRequests, 971, dnsapi.ErrRateLimited},
+		{http.StatusServiceUnavailable, 1, dnsapi.ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatInt(tt.code, 10), func(t *testing.T) {
+			err := wrapError(&cloudflare.Error{
+				StatusCode: tt.status,
+				Errors:     []shared.ErrorData{{Code: tt.code, Message: "message"}},
+			})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("wrapError(%d %d) = %v, 应为 %v", tt.status, tt.code, err, tt.want)
+			}
+			var e *dnsapi.Error
+			if !errors.As(err, &e) || e.Code != strconv.FormatInt(tt.code, 10) || e.Message != "message" {
+				t.Errorf("wrapError(%d %d) 应保留错误码和错误信息", tt.status, tt.code)
+			}
+		})
+	}
+
+	// 没有错误详情时按 HTTP 状态码分类，并读取 Retry-After
+	err := wrapError(&cloudflare.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Response:   &http.Response{Header: http.Header{"Retry-After": {"3"}}},
+	})
+	var e *dnsapi.Error
+	if !errors.As(err, &e) || !errors.Is(err, dnsapi.ErrRateLimited) || e.RetryAfter != 3*time.Second {
+		t.Errorf("限流错误 = %#v, 应为 ErrRateLimited 且 RetryAfter 为 3s", e)
+	}
+}
@@ -0,0 +1,438 @@
+package cloudflare
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/dns"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// recordTypes Cloudflare 支持的记录类型，LOC 记录的格式较复杂，暂不支持
+var recordTypes = append(append([]string{}, dnsapi.RecordTypes...),
+	"PTR", "HTTPS", "SVCB", "URI", "DS", "DNSKEY", "CERT", "SSHFP", "TLSA", "SMIMEA", "NAPTR", "OPENPGPKEY")
+
+// getRecordUnionParam 根据记录类型构造 Cloudflare 的请求参数
+//
+// SRV、CAA、MX 记录使用 param 中的结构化数据，其他使用结构化数据的记录类型（DS、TLSA 等），
+// 记录值需要使用标准的 zone 文件格式，例如 TLSA 记录值为 `3 1 1 0123abcd...`。
+// https://developers.cloudflare.com/api/resources/dns/subresources/records/methods/create/
+func getRecordUnionParam(param *dnsapi.Parameter) (dns.RecordUnionParam, error) {
+	if err := param.ParseData(); err != nil {
+		return nil, err
+	}
+	name := cloudflare.F(fqdn(param.Name, param.Domain))
+	content := cloudflare.F(param.Value)
+	// 没有备注时不发送 comment 字段
+	comment := cloudflare.F(param.Remark)
+	comment.Present = param.Remark != ""
+	ttl := dns.TTL(param.TTL)
+	if ttl == 0 {
+		ttl = dns.TTL1
+	}
+
+	switch param.Type {
+	case "A":
+		return dns.ARecordParam{
+			Name:    name,
+			Type:    cloudflare.F(dns.ARecordTypeA),
+			Content: content,
+			TTL:     cloudflare.F(ttl),
+			Proxied: cloudflare.F(param.Proxied),
+			Comment: comment,
+		}, nil
+	case "AAAA":
+		return dns.AAAARecordParam{
+			Name:    name,
+			Type:    cloudflare.F(dns.AAAARecordTypeAAAA),
+			Content: content,
+			TTL:     cloudflare.F(ttl),
+			Proxied: cloudflare.F(param.Proxied),
+			Comment: comment,
+		}, nil
+	case "CNAME":
+		return dns.CNAMERecordParam{
+			Name:    name,
+			Type:    cloudflare.F(dns.CNAMERecordTypeCNAME),
+			Content: content,
+			TTL:     cloudflare.F(ttl),
+			Proxied: cloudflare.F(param.Proxied),
+			Comment: comment,
+		}, nil
+	case "MX":
+		return dns.MXRecordParam{
+			Name:     name,
+			Type:     cloudflare.F(dns.MXRecordTypeMX),
+			Content:  cloudflare.F(param.MX.Exchange),
+			TTL:      cloudflare.F(ttl),
+			Priority: cloudflare.F(float64(param.MX.Preference)),
+			Comment:  comment,
+		}, nil
+	case "TXT":
+		return dns.TXTRecordParam{
+			Name:    name,
+			Type:    cloudflare.F(dns.TXTRecordTypeTXT),
+			Content: content,
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "NS":
+		return dns.NSRecordParam{
+			Name:    name,
+			Type:    cloudflare.F(dns.NSRecordTypeNS),
+			Content: content,
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "PTR":
+		return dns.PTRRecordParam{
+			Name:    name,
+			Type:    cloudflare.F(dns.PTRRecordTypePTR),
+			Content: content,
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "OPENPGPKEY":
+		return dns.RecordOpenpgpkeyParam{
+			Name:    name,
+			Type:    cloudflare.F(dns.RecordOpenpgpkeyTypeOpenpgpkey),
+			Content: content,
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "SRV":
+		return dns.SRVRecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.SRVRecordTypeSRV),
+			Data: cloudflare.F(dns.SRVRecordDataParam{
+				Priority: cloudflare.F(float64(param.SRV.Priority)),
+				Weight:   cloudflare.F(float64(param.SRV.Weight)),
+				Port:     cloudflare.F(float64(param.SRV.Port)),
+				Target:   cloudflare.F(param.SRV.Target),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "CAA":
+		return dns.CAARecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.CAARecordTypeCAA),
+			Data: cloudflare.F(dns.CAARecordDataParam{
+				Flags: cloudflare.F(float64(param.CAA.Flags)),
+				Tag:   cloudflare.F(param.CAA.Tag),
+				Value: cloudflare.F(param.CAA.Value),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "HTTPS", "SVCB":
+		// 优先级 目标地址 [服务参数...]
+		f, rest, err := cutValue(param, 2)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNumbers(param, f[0])
+		if err != nil {
+			return nil, err
+		}
+		if param.Type == "HTTPS" {
+			return dns.HTTPSRecordParam{
+				Name: name,
+				Type: cloudflare.F(dns.HTTPSRecordTypeHTTPS),
+				Data: cloudflare.F(dns.HTTPSRecordDataParam{
+					Priority: cloudflare.F(n[0]),
+					Target:   cloudflare.F(f[1]),
+					Value:    cloudflare.F(rest),
+				}),
+				TTL:     cloudflare.F(ttl),
+				Comment: comment,
+			}, nil
+		}
+		return dns.SVCBRecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.SVCBRecordTypeSVCB),
+			Data: cloudflare.F(dns.SVCBRecordDataParam{
+				Priority: cloudflare.F(n[0]),
+				Target:   cloudflare.F(f[1]),
+				Value:    cloudflare.F(rest),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "URI":
+		// 优先级 权重 "目标地址"，省略优先级时使用 param.Priority
+		f, err := splitValue(param, 2, 3)
+		if err != nil {
+			return nil, err
+		}
+		if len(f) == 2 {
+			f = append([]string{strconv.Itoa(param.Priority)}, f...)
+		}
+		n, err := parseNumbers(param, f[:2]...)
+		if err != nil {
+			return nil, err
+		}
+		return dns.URIRecordParam{
+			Name:     name,
+			Type:     cloudflare.F(dns.URIRecordTypeURI),
+			Priority: cloudflare.F(n[0]),
+			Data: cloudflare.F(dns.URIRecordDataParam{
+				Weight: cloudflare.F(n[1]),
+				Target: cloudflare.F(f[2]),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "DS":
+		// 密钥标签 算法 摘要类型 摘要
+		f, err := splitValue(param, 4, 4)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNumbers(param, f[:3]...)
+		if err != nil {
+			return nil, err
+		}
+		return dns.DSRecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.DSRecordTypeDS),
+			Data: cloudflare.F(dns.DSRecordDataParam{
+				KeyTag:     cloudflare.F(n[0]),
+				Algorithm:  cloudflare.F(n[1]),
+				DigestType: cloudflare.F(n[2]),
+				Digest:     cloudflare.F(f[3]),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "DNSKEY":
+		// 标志 协议 算法 公钥
+		f, err := splitValue(param, 4, 4)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNumbers(param, f[:3]...)
+		if err != nil {
+			return nil, err
+		}
+		return dns.DNSKEYRecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.DNSKEYRecordTypeDNSKEY),
+			Data: cloudflare.F(dns.DNSKEYRecordDataParam{
+				Flags:     cloudflare.F(n[0]),
+				Protocol:  cloudflare.F(n[1]),
+				Algorithm: cloudflare.F(n[2]),
+				PublicKey: cloudflare.F(f[3]),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "CERT":
+		// 证书类型 密钥标签 算法 证书
+		f, err := splitValue(param, 4, 4)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNumbers(param, f[:3]...)
+		if err != nil {
+			return nil, err
+		}
+		return dns.CERTRecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.CERTRecordTypeCERT),
+			Data: cloudflare.F(dns.CERTRecordDataParam{
+				Type:        cloudflare.F(n[0]),
+				KeyTag:      cloudflare.F(n[1]),
+				Algorithm:   cloudflare.F(n[2]),
+				Certificate: cloudflare.F(f[3]),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "SSHFP":
+		// 算法 指纹类型 指纹
+		f, err := splitValue(param, 3, 3)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNumbers(param, f[:2]...)
+		if err != nil {
+			return nil, err
+		}
+		return dns.SSHFPRecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.SSHFPRecordTypeSSHFP),
+			Data: cloudflare.F(dns.SSHFPRecordDataParam{
+				Algorithm:   cloudflare.F(n[0]),
+				Type:        cloudflare.F(n[1]),
+				Fingerprint: cloudflare.F(f[2]),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "TLSA", "SMIMEA":
+		// 证书用途 选择器 匹配类型 证书数据
+		f, err := splitValue(param, 4, 4)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNumbers(param, f[:3]...)
+		if err != nil {
+			return nil, err
+		}
+		if param.Type == "TLSA" {
+			return dns.TLSARecordParam{
+				Name: name,
+				Type: cloudflare.F(dns.TLSARecordTypeTLSA),
+				Data: cloudflare.F(dns.TLSARecordDataParam{
+					Usage:        cloudflare.F(n[0]),
+					Selector:     cloudflare.F(n[1]),
+					MatchingType: cloudflare.F(n[2]),
+					Certificate:  cloudflare.F(f[3]),
+				}),
+				TTL:     cloudflare.F(ttl),
+				Comment: comment,
+			}, nil
+		}
+		return dns.SMIMEARecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.SMIMEARecordTypeSMIMEA),
+			Data: cloudflare.F(dns.SMIMEARecordDataParam{
+				Usage:        cloudflare.F(n[0]),
+				Selector:     cloudflare.F(n[1]),
+				MatchingType: cloudflare.F(n[2]),
+				Certificate:  cloudflare.F(f[3]),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	case "NAPTR":
+		// 顺序 优先级 "标志" "服务" "正则表达式" 替换域名
+		f, err := splitValue(param, 6, 6)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNumbers(param, f[:2]...)
+		if err != nil {
+			return nil, err
+		}
+		return dns.NAPTRRecordParam{
+			Name: name,
+			Type: cloudflare.F(dns.NAPTRRecordTypeNAPTR),
+			Data: cloudflare.F(dns.NAPTRRecordDataParam{
+				Order:       cloudflare.F(n[0]),
+				Preference:  cloudflare.F(n[1]),
+				Flags:       cloudflare.F(f[2]),
+				Service:     cloudflare.F(f[3]),
+				Regex:       cloudflare.F(f[4]),
+				Replacement: cloudflare.F(f[5]),
+			}),
+			TTL:     cloudflare.F(ttl),
+			Comment: comment,
+		}, nil
+	}
+	return nil, dnsapi.Errorf(dnsapi.ErrUnsupported, "Cloudflare 暂不支持此记录类型：%s", param.Type)
+}
+
+// fqdn 返回主机记录对应的完整域名，@ 表示域名本身
+func fqdn(name, domain string) string {
+	if name == "" || name == "@" {
+		return domain
+	}
+	return name + "." + domain
+}
+
+// relativeName 返回完整域名对应的主机记录，与阿里云和 DNSPod 一致，域名本身为 @
+func relativeName(name, domain string) string {
+	if name == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+// splitValue 按空白字符拆分记录值，双引号中的内容作为一个字段并去掉引号，
+// 字段数量需要在 [min, max] 范围内
+func splitValue(param *dnsapi.Parameter, min, max int) ([]string, error) {
+	var (
+		fields  []string
+		field   strings.Builder
+		quoted  bool
+		inField bool
+	)
+	for _, c := range param.Value {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			inField = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(c)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	if quoted || len(fields) < min || len(fields) > max {
+		return nil, invalidValue(param)
+	}
+	return fields, nil
+}
+
+// cutValue 拆分出记录值开头的 n 个字段，剩余部分原样返回
+func cutValue(param *dnsapi.Parameter, n int) ([]string, string, error) {
+	rest := strings.TrimSpace(param.Value)
+	fields := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if rest == "" {
+			return nil, "", invalidValue(param)
+		}
+		field, after, _ := strings.Cut(rest, " ")
+		fields = append(fields, field)
+		rest = strings.TrimSpace(after)
+	}
+	return fields, rest, nil
+}
+
+// parseNumbers 将字段解析为数字
+func parseNumbers(param *dnsapi.Parameter, fields ...string) ([]float64, error) {
+	numbers := make([]float64, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, invalidValue(param)
+		}
+		numbers = append(numbers, float64(n))
+	}
+	return numbers, nil
+}
+
+func invalidValue(param *dnsapi.Parameter) error {
+	return dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的 %s 记录值：%s", param.Type, param.Value)
+}
+
+// recordValue 返回与其他服务商一致的记录值，Cloudflare 的 SRV 记录 content 中不包含优先级，
+// CAA 记录 content 中的值不带引号，这两类记录从结构化数据中生成
+func recordValue(v dns.RecordResponse) string {
+	switch r := v.AsUnion().(type) {
+	case dns.RecordResponseSRV:
+		srv := dnsapi.SRV{
+			Priority: int(r.Data.Priority),
+			Weight:   int(r.Data.Weight),
+			Port:     int(r.Data.Port),
+			Target:   r.Data.Target,
+		}
+		return srv.String()
+	case dns.RecordResponseCAA:
+		caa := dnsapi.CAA{Flags: int(r.Data.Flags), Tag: r.Data.Tag, Value: r.Data.Value}
+		return caa.String()
+	}
+	return v.Content
+}
@@ -1,6 +1,6 @@
 package dnsapi
 
-import "fmt"
+import "context"
 
 // RecordTypes 表示DNS记录类型
 var RecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA"}
@@ -11,13 +11,49 @@ func ValidRecordType(rType string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("无效的 DNS 记录类型：%s", rType)
+	return Errorf(ErrInvalidInput, "无效的 DNS 记录类型：%s", rType)
 }
 
+// Domain 表示服务商中托管的一个域名
 type Domain struct {
-	DomainName string
-	Status     bool   // ali: InstanceExpired tencent: Status
-	CreateTime string // ali: CreateTime      tencent: CreatedOn
+	ID          string   `json:"id" table:"域名ID"`
+	Name        string   `json:"name" table:"域名"`
+	Status      string   `json:"status,omitempty" table:"状态"`           // 服务商返回的域名状态，统一为小写
+	RecordCount int      `json:"record_count" table:"记录数"`              // Cloudflare 不返回记录数，始终为 0
+	Plan        string   `json:"plan,omitempty" table:"套餐"`             // 套餐版本，如免费版
+	NameServers []string `json:"name_servers,omitempty" table:"DNS服务器"` // 服务商分配的 DNS 服务器
+	Created     string   `json:"created,omitempty" table:"创建时间"`
+}
+
+// Line 表示域名可用的一条解析线路
+type Line struct {
+	Code   string `json:"code" table:"线路代码"`             // 解析记录中使用的线路值，即 Record.Line 和 Parameter.Line
+	Name   string `json:"name" table:"线路名"`              // 线路显示名称
+	Parent string `json:"parent,omitempty" table:"上级线路"` // 上级线路代码，没有时为空
+}
+
+// FindLine 按线路代码或名称查找线路
+func FindLine(lines []Line, line string) (*Line, error) {
+	for i := range lines {
+		if lines[i].Code == line || lines[i].Name == line {
+			return &lines[i], nil
+		}
+	}
+	return nil, Errorf(ErrInvalidInput, "域名不支持此解析线路：%s", line)
+}
+
+// 解析记录状态
+const (
+	StatusEnable  = "ENABLE"
+	StatusDisable = "DISABLE"
+)
+
+// ValidStatus 判断记录状态是否有效
+func ValidStatus(status string) error {
+	if status == StatusEnable || status == StatusDisable {
+		return nil
+	}
+	return Errorf(ErrInvalidInput, "无效的记录状态：%s", status)
 }
 
 // Record 表示一条DNS记录
@@ -30,8 +66,39 @@ type Record struct {
 	TTL      int    `json:"ttl"`
 	Line     string `json:"line,omitempty" table:"线路名"`
 	Priority int    `json:"priority,omitempty" table:"优先级"` // 用于MX和SRV记录
+	Weight   int    `json:"weight,omitempty" table:"权重"`    // 同名同类型多条记录的负载均衡权重
 	Proxied  bool   `json:"proxied,omitempty"`              // 适用于 Cloudflare
+	Status   string `json:"status,omitempty" table:"状态"`    // StatusEnable 或 StatusDisable
+	Remark   string `json:"remark,omitempty" table:"备注"`
 	Updated  string `json:"updated,omitempty" table:"更新时间"`
+	SRV      *SRV   `json:"srv,omitempty" table:"-"` // SRV 记录的结构化数据
+	CAA      *CAA   `json:"caa,omitempty" table:"-"` // CAA 记录的结构化数据
+	MX       *MX    `json:"mx,omitempty" table:"-"`  // MX 记录的结构化数据
+}
+
+// FQDN 返回记录的完整域名，主机记录为 @ 时即为域名本身
+func (r *Record) FQDN() string {
+	if r.Name == "" || r.Name == "@" {
+		return r.Domain
+	}
+	return r.Name + "." + r.Domain
+}
+
+// Parameter 返回以记录为初始值的参数，用于修改或删除该记录
+func (r *Record) Parameter() *Parameter {
+	return &Parameter{
+		ID:       r.ID,
+		Domain:   r.Domain,
+		Name:     r.Name,
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      r.TTL,
+		Line:     r.Line,
+		Priority: r.Priority,
+		Weight:   r.Weight,
+		Proxied:  r.Proxied,
+		Remark:   r.Remark,
+	}
 }
 
 // Parameter 解析请求参数
@@ -52,12 +119,20 @@ type Parameter struct {
 	Line string
 	// Priority 优先级，用于MX和SRV记录
 	Priority int
+	// Weight 权重，为 0 时不设置
+	Weight int
+	// ZeroWeight 将权重设置为 0，仅用于 Capabilities.MinWeight 为 0 的服务商
+	ZeroWeight bool
 	// Proxied 是否启用Cloudflare代理
 	Proxied bool
-	// Status 记录状态
+	// Status 记录状态，取值 StatusEnable 或 StatusDisable
 	Status string
 	// Remark 备注信息
 	Remark string
+	// SRV、CAA、MX 记录的结构化数据，为 nil 时从 Value 中解析，见 ParseData
+	SRV *SRV
+	CAA *CAA
+	MX  *MX
 }
 
 func CreateParameter(domain string) *Parameter {
@@ -65,22 +140,40 @@ func CreateParameter(domain string) *Parameter {
 }
 
 // DNSAPI 定义 DNS API 接口
+//
+// 所有方法都接收 context.Context，调用方通过它控制超时和取消，
+// 实现方需要保证 ctx 被取消后尽快返回。
 type DNSAPI interface {
 	// ListRecords 获取指定域名的所有解析记录
-	ListRecords(param *Parameter) ([]Record, error)
+	ListRecords(ctx context.Context, param *Parameter) ([]Record, error)
 
 	// GetRecord 获取特定记录的详情
-	GetRecord(param *Parameter) (*Record, error)
+	GetRecord(ctx context.Context, param *Parameter) (*Record, error)
 
-	// AddRecord 添加新的解析记录
-	AddRecord(param *Parameter) error
+	// AddRecord 添加新的解析记录，成功后将新记录的 ID 写入 param.ID
+	AddRecord(ctx context.Context, param *Parameter) error
 
 	// UpdateRecord 更新现有解析记录
-	UpdateRecord(param *Parameter) error
+	UpdateRecord(ctx context.Context, param *Parameter) error
 
 	// DeleteRecord 删除解析记录
-	DeleteRecord(param *Parameter) error
+	DeleteRecord(ctx context.Context, param *Parameter) error
+
+	// SetRecordStatus 启用或暂停解析记录，状态由 param.Status 指定
+	SetRecordStatus(ctx context.Context, param *Parameter) error
+
+	// ListLines 列出域名当前套餐支持的解析线路
+	ListLines(ctx context.Context, domain string) ([]Line, error)
 
 	// ListDomains 列出账号下所有域名
-	ListDomains() ([]string, error)
+	ListDomains(ctx context.Context) ([]Domain, error)
+
+	// AddDomain 添加域名，返回的 Domain 中包含服务商分配的 DNS 服务器
+	AddDomain(ctx context.Context, domain string) (*Domain, error)
+
+	// DeleteDomain 删除域名及其所有解析记录
+	DeleteDomain(ctx context.Context, domain string) error
+
+	// Capabilities 返回服务商支持的功能
+	Capabilities() Capabilities
 }
@@ -0,0 +1,534 @@
+// Package dnsapitest 提供 dnsapi.DNSAPI 实现的一致性测试，
+// 保证各服务商在记录名称、过滤条件、分页和错误分类等方面的行为一致。
+//
+// 服务商的测试中调用 Run 即可：
+//
+//	func TestConformance(t *testing.T) {
+//		dnsapitest.Run(t, dnsapitest.Config{
+//			Domain: "example.com",
+//			New:    func(t *testing.T) dnsapi.DNSAPI { ... },
+//		})
+//	}
+package dnsapitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// Config 一致性测试的配置
+type Config struct {
+	// Domain 测试使用的域名
+	Domain string
+	// New 创建被测试的客户端，每个子测试调用一次，返回的客户端中 Domain 必须已存在且没有解析记录
+	New func(t *testing.T) dnsapi.DNSAPI
+	// MissingID 不存在的记录 ID，需要符合服务商的 ID 格式，默认为 999999999
+	MissingID string
+	// PageRecords 分页测试中创建的记录数，需要大于服务商每页的记录数，默认为 120
+	PageRecords int
+	// SkipDomains 跳过域名管理的测试
+	SkipDomains bool
+}
+
+// Run 运行一致性测试
+func Run(t *testing.T, cfg Config) {
+	if cfg.MissingID == "" {
+		cfg.MissingID = "999999999"
+	}
+	if cfg.PageRecords == 0 {
+		cfg.PageRecords = 120
+	}
+	s := &suite{cfg: cfg}
+
+	t.Run("CRUD", s.testCRUD)
+	t.Run("NameNormalization", s.testNameNormalization)
+	t.Run("ListFilters", s.testListFilters)
+	t.Run("Pagination", s.testPagination)
+	t.Run("StructuredData", s.testStructuredData)
+	t.Run("Status", s.testStatus)
+	t.Run("Remark", s.testRemark)
+	t.Run("Weight", s.testWeight)
+	t.Run("Errors", s.testErrors)
+	t.Run("Batch", s.testBatch)
+	t.Run("BatchAttributes", s.testBatchAttributes)
+	if !cfg.SkipDomains {
+		t.Run("Domains", s.testDomains)
+	}
+}
+
+type suite struct {
+	cfg Config
+}
+
+// param 返回测试域名下的请求参数
+func (s *suite) param(name, rType, value string) *dnsapi.Parameter {
+	p := dnsapi.CreateParameter(s.cfg.Domain)
+	p.Name = name
+	p.Type = rType
+	p.Value = value
+	return p
+}
+
+// add 添加记录并返回添加后的记录，AddRecord 应将新记录的 ID 写入 p.ID
+func (s *suite) add(t *testing.T, api dnsapi.DNSAPI, p *dnsapi.Parameter) dnsapi.Record {
+	t.Helper()
+	if err := api.AddRecord(context.Background(), p); err != nil {
+		t.Fatalf("AddRecord(%s %s %s): %v", p.Name, p.Type, p.Value, err)
+	}
+	record := s.find(t, api, p.Name, p.Type, p.Value)
+	if p.ID != record.ID {
+		t.Errorf("AddRecord 返回的记录 ID = %q, 应为 %q", p.ID, record.ID)
+	}
+	return record
+}
+
+// find 返回指定主机记录、类型和记录值的唯一记录
+func (s *suite) find(t *testing.T, api dnsapi.DNSAPI, name, rType, value string) dnsapi.Record {
+	t.Helper()
+	records := s.list(t, api, s.param(name, rType, ""))
+	var found []dnsapi.Record
+	for _, r := range records {
+		if r.Name == name && r.Type == rType && r.Value == value {
+			found = append(found, r)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("ListRecords 中找到 %d 条 %s %s %s 记录，应为 1 条，返回的记录: %+v", len(found), name, rType, value, records)
+	}
+	return found[0]
+}
+
+func (s *suite) list(t *testing.T, api dnsapi.DNSAPI, p *dnsapi.Parameter) []dnsapi.Record {
+	t.Helper()
+	records, err := api.ListRecords(context.Background(), p)
+	if err != nil {
+		t.Fatalf("ListRecords: %v", err)
+	}
+	return records
+}
+
+func (s *suite) get(t *testing.T, api dnsapi.DNSAPI, id string) *dnsapi.Record {
+	t.Helper()
+	p := dnsapi.CreateParameter(s.cfg.Domain)
+	p.ID = id
+	record, err := api.GetRecord(context.Background(), p)
+	if err != nil {
+		t.Fatalf("GetRecord(%s): %v", id, err)
+	}
+	return record
+}
+
+// testCRUD 添加、查询、更新和删除记录
+func (s *suite) testCRUD(t *testing.T) {
+	api := s.cfg.New(t)
+	ctx := context.Background()
+
+	p := s.param("www", "A", "192.0.2.1")
+	p.TTL = api.Capabilities().MinTTL
+	if p.TTL < 600 {
+		p.TTL = 600
+	}
+	created := s.add(t, api, p)
+	if created.ID == "" {
+		t.Fatal("ListRecords 返回的记录 ID 为空")
+	}
+	if created.Domain != s.cfg.Domain {
+		t.Errorf("Domain = %q, 应为 %q", created.Domain, s.cfg.Domain)
+	}
+	if created.TTL != p.TTL {
+		t.Errorf("TTL = %d, 应为 %d", created.TTL, p.TTL)
+	}
+	if api.Capabilities().Updated {
+		if _, err := time.ParseInLocation(time.DateTime, created.Updated, time.Local); err != nil {
+			t.Errorf("Updated = %q, 应为 %s 格式的更新时间", created.Updated, time.DateTime)
+		}
+	}
+
+	got := s.get(t, api, created.ID)
+	assertSameRecord(t, "GetRecord", got, &created)
+
+	update := s.param("www", "A", "192.0.2.2")
+	update.ID = created.ID
+	update.TTL = p.TTL
+	if err := api.UpdateRecord(ctx, update); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+	got = s.get(t, api, created.ID)
+	if got.Value != "192.0.2.2" {
+		t.Errorf("更新后 GetRecord 的 Value = %q, 应为 192.0.2.2", got.Value)
+	}
+	updated := s.find(t, api, "www", "A", "192.0.2.2")
+	if updated.ID != created.ID {
+		t.Errorf("更新后记录 ID = %q, 应保持为 %q", updated.ID, created.ID)
+	}
+
+	del := dnsapi.CreateParameter(s.cfg.Domain)
+	del.ID = created.ID
+	if err := api.DeleteRecord(ctx, del); err != nil {
+		t.Fatalf("DeleteRecord: %v", err)
+	}
+	if _, err := api.GetRecord(ctx, del); !errors.Is(err, dnsapi.ErrNotFound) {
+		t.Errorf("删除后 GetRecord 的错误 = %v, 应为 ErrNotFound", err)
+	}
+	for _, r := range s.list(t, api, s.param("www", "", "")) {
+		if r.ID == created.ID {
+			t.Errorf("删除后 ListRecords 仍然返回记录 %s", created.ID)
+		}
+	}
+}
+
+// testNameNormalization ListRecords 和 GetRecord 返回相对于域名的主机记录，域名本身为 @
+func (s *suite) testNameNormalization(t *testing.T) {
+	api := s.cfg.New(t)
+	for _, name := range []string{"@", "www", "a.b"} {
+		record := s.add(t, api, s.param(name, "TXT", "name-"+name))
+		got := s.get(t, api, record.ID)
+		if got.Name != name {
+			t.Errorf("GetRecord 返回的主机记录 = %q, 应为 %q", got.Name, name)
+		}
+		assertSameRecord(t, "GetRecord", got, &record)
+	}
+}
+
+// testListFilters ListRecords 按主机记录、类型和记录值过滤，服务商可以精确匹配或模糊匹配主机记录和记录值
+func (s *suite) testListFilters(t *testing.T) {
+	api := s.cfg.New(t)
+	s.add(t, api, s.param("www", "A", "192.0.2.1"))
+	s.add(t, api, s.param("www", "TXT", "hello"))
+	s.add(t, api, s.param("api", "A", "192.0.2.2"))
+
+	all := s.list(t, api, dnsapi.CreateParameter(s.cfg.Domain))
+	if len(all) < 3 {
+		t.Fatalf("ListRecords 返回 %d 条记录, 应至少为 3 条", len(all))
+	}
+
+	byType := s.list(t, api, s.param("", "A", ""))
+	assertContains(t, byType, "www", "A", "api", "A")
+	for _, r := range byType {
+		if r.Type != "A" {
+			t.Errorf("按类型 A 过滤返回了 %s 记录", r.Type)
+		}
+	}
+
+	byName := s.list(t, api, s.param("www", "", ""))
+	assertContains(t, byName, "www", "A", "www", "TXT")
+	for _, r := range byName {
+		if !strings.Contains(r.Name, "www") {
+			t.Errorf("按主机记录 www 过滤返回了 %s", r.Name)
+		}
+	}
+
+	byValue := s.param("", "", "192.0.2.2")
+	for _, r := range s.list(t, api, byValue) {
+		if !strings.Contains(r.Value, byValue.Value) {
+			t.Errorf("按记录值过滤返回了 %s", r.Value)
+		}
+	}
+	assertContains(t, s.list(t, api, byValue), "api", "A")
+}
+
+// testPagination ListRecords 返回所有分页的记录
+func (s *suite) testPagination(t *testing.T) {
+	api := s.cfg.New(t)
+	ctx := context.Background()
+	for i := 0; i < s.cfg.PageRecords; i++ {
+		p := s.param(fmt.Sprintf("page%03d", i), "A", "192.0.2.10")
+		if err := api.AddRecord(ctx, p); err != nil {
+			t.Fatalf("AddRecord(%s): %v", p.Name, err)
+		}
+	}
+	// 有的服务商只能精确匹配主机记录，这里按类型查询再按前缀过滤
+	records := s.list(t, api, s.param("", "A", ""))
+	ids := make(map[string]bool)
+	for _, r := range records {
+		if strings.HasPrefix(r.Name, "page") {
+			ids[r.ID] = true
+		}
+	}
+	if len(ids) != s.cfg.PageRecords {
+		t.Errorf("ListRecords 返回 %d 条不重复的记录, 应为 %d 条", len(ids), s.cfg.PageRecords)
+	}
+}
+
+// testStructuredData SRV、CAA、MX 记录使用统一的记录值格式和结构化数据
+func (s *suite) testStructuredData(t *testing.T) {
+	api := s.cfg.New(t)
+	caps := api.Capabilities()
+
+	if caps.ValidRecordType("MX") == nil {
+		p := s.param("@", "MX", "10 mx.example.net")
+		mx := s.add(t, api, p)
+		if mx.Priority != 10 || mx.MX == nil || mx.MX.Exchange != "mx.example.net" {
+			t.Errorf("MX 记录 = %+v, 应为优先级 10 的 mx.example.net", mx)
+		}
+	}
+	if caps.ValidRecordType("SRV") == nil {
+		p := s.param("_sip._tcp", "SRV", "10 5 5060 sip.example.net")
+		srv := s.add(t, api, p)
+		want := dnsapi.SRV{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.net"}
+		if srv.SRV == nil || *srv.SRV != want || srv.Priority != 10 {
+			t.Errorf("SRV 记录 = %+v, 应为 %+v", srv, want)
+		}
+	}
+	if caps.ValidRecordType("CAA") == nil {
+		p := s.param("@", "CAA", "")
+		p.CAA = &dnsapi.CAA{Tag: "issue", Value: "letsencrypt.org"}
+		caa := s.add(t, api, p)
+		if caa.CAA == nil || caa.CAA.Tag != "issue" || caa.CAA.Value != "letsencrypt.org" {
+			t.Errorf("CAA 记录 = %+v, 应为 0 issue \"letsencrypt.org\"", caa)
+		}
+	}
+}
+
+// testStatus 暂停和启用记录
+func (s *suite) testStatus(t *testing.T) {
+	api := s.cfg.New(t)
+	ctx := context.Background()
+	record := s.add(t, api, s.param("status", "A", "192.0.2.1"))
+
+	p := dnsapi.CreateParameter(s.cfg.Domain)
+	p.ID = record.ID
+	p.Status = dnsapi.StatusDisable
+	err := api.SetRecordStatus(ctx, p)
+	if !api.Capabilities().Status {
+		if !errors.Is(err, dnsapi.ErrUnsupported) {
+			t.Errorf("不支持记录状态时 SetRecordStatus 的错误 = %v, 应为 ErrUnsupported", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("SetRecordStatus: %v", err)
+	}
+	disabled := s.find(t, api, "status", "A", "192.0.2.1")
+	if disabled.Status != dnsapi.StatusDisable {
+		t.Errorf("暂停后 Status = %q, 应为 %q", disabled.Status, dnsapi.StatusDisable)
+	}
+	assertSameRecord(t, "暂停后 GetRecord", s.get(t, api, record.ID), &disabled)
+
+	p.Status = "PAUSED"
+	if err := api.SetRecordStatus(ctx, p); !errors.Is(err, dnsapi.ErrInvalidInput) {
+		t.Errorf("无效状态的错误 = %v, 应为 ErrInvalidInput", err)
+	}
+}
+
+// testRemark 添加记录时设置备注，更新时没有指定备注则保留原来的备注
+func (s *suite) testRemark(t *testing.T) {
+	api := s.cfg.New(t)
+	if !api.Capabilities().Remark {
+		t.Skip("服务商不支持记录备注")
+	}
+	p := s.param("remark", "A", "192.0.2.1")
+	p.Remark = "conformance"
+	got := s.add(t, api, p)
+	if got.Remark != "conformance" {
+		t.Errorf("Remark = %q, 应为 conformance", got.Remark)
+	}
+	assertSameRecord(t, "GetRecord", s.get(t, api, got.ID), &got)
+
+	update := s.param("remark", "A", "192.0.2.2")
+	update.ID = got.ID
+	if err := api.UpdateRecord(context.Background(), update); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+	if updated := s.get(t, api, got.ID); updated.Remark != "conformance" {
+		t.Errorf("不指定备注更新后 Remark = %q, 应为 conformance", updated.Remark)
+	}
+}
+
+// testWeight 添加和更新记录时设置权重
+func (s *suite) testWeight(t *testing.T) {
+	api := s.cfg.New(t)
+	if !api.Capabilities().Weight {
+		t.Skip("服务商不支持记录权重")
+	}
+	var records []dnsapi.Record
+	for i, value := range []string{"192.0.2.1", "192.0.2.2"} {
+		p := s.param("weight", "A", value)
+		p.Weight = 10 * (i + 1)
+		records = append(records, s.add(t, api, p))
+	}
+	for i, record := range records {
+		if record.Weight != 10*(i+1) {
+			t.Errorf("%s 的 Weight = %d, 应为 %d", record.Value, record.Weight, 10*(i+1))
+		}
+		assertSameRecord(t, "GetRecord", s.get(t, api, record.ID), &record)
+	}
+
+	// 更新时不指定权重保持原值，支持权重 0 的服务商可以将权重设置为 0
+	update := records[0].Parameter()
+	update.Weight = 0
+	if err := api.UpdateRecord(context.Background(), update); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+	if got := s.get(t, api, records[0].ID); got.Weight != 10 {
+		t.Errorf("不指定权重更新后 Weight = %d, 应为 10", got.Weight)
+	}
+	if api.Capabilities().MinWeight == 0 {
+		update.ZeroWeight = true
+		if err := api.UpdateRecord(context.Background(), update); err != nil {
+			t.Fatalf("UpdateRecord: %v", err)
+		}
+		if got := s.get(t, api, records[0].ID); got.Weight != 0 {
+			t.Errorf("设置权重为 0 后 Weight = %d, 应为 0", got.Weight)
+		}
+	}
+}
+
+// testErrors 错误需要能用 errors.Is 判断分类
+func (s *suite) testErrors(t *testing.T) {
+	api := s.cfg.New(t)
+	ctx := context.Background()
+
+	missing := dnsapi.CreateParameter(s.cfg.Domain)
+	missing.ID = s.cfg.MissingID
+	if _, err := api.GetRecord(ctx, missing); !errors.Is(err, dnsapi.ErrNotFound) {
+		t.Errorf("GetRecord 不存在的记录的错误 = %v, 应为 ErrNotFound", err)
+	}
+	if err := api.DeleteRecord(ctx, missing); !errors.Is(err, dnsapi.ErrNotFound) {
+		t.Errorf("DeleteRecord 不存在的记录的错误 = %v, 应为 ErrNotFound", err)
+	}
+
+	unknown := dnsapi.CreateParameter("missing-" + s.cfg.Domain)
+	if _, err := api.ListRecords(ctx, unknown); !errors.Is(err, dnsapi.ErrNotFound) {
+		t.Errorf("ListRecords 不存在的域名的错误 = %v, 应为 ErrNotFound", err)
+	}
+
+	s.add(t, api, s.param("dup", "A", "192.0.2.1"))
+	if err := api.AddRecord(ctx, s.param("dup", "A", "192.0.2.1")); !errors.Is(err, dnsapi.ErrConflict) {
+		t.Errorf("添加重复记录的错误 = %v, 应为 ErrConflict", err)
+	}
+
+	err := api.AddRecord(ctx, s.param("bad", "BOGUS", "x"))
+	if !errors.Is(err, dnsapi.ErrInvalidInput) && !errors.Is(err, dnsapi.ErrUnsupported) {
+		t.Errorf("无效记录类型的错误 = %v, 应为 ErrInvalidInput 或 ErrUnsupported", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := api.ListRecords(canceled, dnsapi.CreateParameter(s.cfg.Domain)); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx 取消后 ListRecords 的错误 = %v, 应为 context.Canceled", err)
+	}
+}
+
+// testBatch ApplyChanges 返回每项修改的结果，单项失败不影响其他修改
+func (s *suite) testBatch(t *testing.T) {
+	api := s.cfg.New(t)
+	existing := s.add(t, api, s.param("batch", "A", "192.0.2.1"))
+
+	changes := []dnsapi.Change{
+		{Action: dnsapi.ChangeCreate, Param: s.param("batch", "A", "192.0.2.2")},
+		{Action: dnsapi.ChangeDelete, Param: &dnsapi.Parameter{ID: existing.ID}},
+	}
+	results, err := dnsapi.ApplyChanges(context.Background(), api, s.cfg.Domain, changes)
+	if err != nil {
+		t.Fatalf("ApplyChanges: %v", err)
+	}
+	if len(results) != len(changes) {
+		t.Fatalf("ApplyChanges 返回 %d 条结果, 应为 %d 条", len(results), len(changes))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Change, r.Err)
+		}
+	}
+	records := s.list(t, api, s.param("batch", "A", ""))
+	assertContains(t, records, "batch", "A")
+	for _, r := range records {
+		if r.ID == existing.ID {
+			t.Errorf("批量删除后仍然存在记录 %s", existing.ID)
+		}
+	}
+}
+
+// testBatchAttributes ApplyChanges 添加记录时保留权重和备注
+func (s *suite) testBatchAttributes(t *testing.T) {
+	api := s.cfg.New(t)
+	caps := api.Capabilities()
+	if !caps.Weight && !caps.Remark {
+		t.Skip("服务商不支持记录权重和备注")
+	}
+	p := s.param("batch-attrs", "A", "192.0.2.1")
+	if caps.Weight {
+		p.Weight = 20
+	}
+	if caps.Remark {
+		p.Remark = "conformance"
+	}
+	results, err := dnsapi.ApplyChanges(context.Background(), api, s.cfg.Domain, []dnsapi.Change{{Action: dnsapi.ChangeCreate, Param: p}})
+	if err != nil {
+		t.Fatalf("ApplyChanges: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("%s: %v", results[0].Change, results[0].Err)
+	}
+	got := s.find(t, api, p.Name, p.Type, p.Value)
+	if got.Weight != p.Weight {
+		t.Errorf("Weight = %d, 应为 %d", got.Weight, p.Weight)
+	}
+	if got.Remark != p.Remark {
+		t.Errorf("Remark = %q, 应为 %q", got.Remark, p.Remark)
+	}
+	assertSameRecord(t, "GetRecord", s.get(t, api, got.ID), &got)
+}
+
+// testDomains 添加、查询和删除域名
+func (s *suite) testDomains(t *testing.T) {
+	api := s.cfg.New(t)
+	ctx := context.Background()
+	name := "conformance-" + s.cfg.Domain
+
+	domain, err := api.AddDomain(ctx, name)
+	if err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	if domain.Name != name {
+		t.Errorf("AddDomain 返回的域名 = %q, 应为 %q", domain.Name, name)
+	}
+	if _, err := api.AddDomain(ctx, name); !errors.Is(err, dnsapi.ErrConflict) {
+		t.Errorf("重复添加域名的错误 = %v, 应为 ErrConflict", err)
+	}
+
+	domains, err := api.ListDomains(ctx)
+	if err != nil {
+		t.Fatalf("ListDomains: %v", err)
+	}
+	if !slices.ContainsFunc(domains, func(d dnsapi.Domain) bool { return d.Name == name }) {
+		t.Errorf("ListDomains 中没有新添加的域名 %s", name)
+	}
+
+	if err := api.DeleteDomain(ctx, name); err != nil {
+		t.Fatalf("DeleteDomain: %v", err)
+	}
+	if err := api.DeleteDomain(ctx, name); !errors.Is(err, dnsapi.ErrNotFound) {
+		t.Errorf("删除不存在的域名的错误 = %v, 应为 ErrNotFound", err)
+	}
+}
+
+// assertSameRecord 比较 GetRecord 和 ListRecords 返回的同一条记录，除更新时间外所有字段都应相同
+func assertSameRecord(t *testing.T, method string, got, want *dnsapi.Record) {
+	t.Helper()
+	g, w := *got, *want
+	g.Updated, w.Updated = "", ""
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("%s 返回 %+v, 与 ListRecords 返回的 %+v 不一致", method, got, want)
+	}
+}
+
+// assertContains 检查 records 中包含指定的记录，pairs 为依次排列的主机记录和类型
+func assertContains(t *testing.T, records []dnsapi.Record, pairs ...string) {
+	t.Helper()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		name, rType := pairs[i], pairs[i+1]
+		if !slices.ContainsFunc(records, func(r dnsapi.Record) bool { return r.Name == name && r.Type == rType }) {
+			t.Errorf("结果中缺少 %s %s 记录", name, rType)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package dnsapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// PlannedChange dry-run 模式下被拦截的一项修改
+type PlannedChange struct {
+	// Call 被拦截的调用，ApplyChanges 中的每一项会拆分为单独的 AddRecord、UpdateRecord、DeleteRecord
+	Call *Call
+	// Before 修改前的记录，添加记录和修改域名时为 nil
+	Before *Record
+	// After 根据参数推算的修改后的记录，删除记录和修改域名时为 nil
+	After *Record
+}
+
+// DryRun 返回不执行修改操作的中间件，查询操作正常执行
+//
+// 修改、删除和启停记录前通过 GetRecord 查询修改前的记录，添加和删除域名前检查域名是否存在，
+// 无法执行时与实际执行一样返回错误；之后把修改前后的记录交给 report 输出并直接返回成功。ApplyChanges 中任意一项无法执行时整批返回错误。
+func DryRun(report func(PlannedChange)) Middleware {
+	return func(api DNSAPI) DNSAPI {
+		plan := func(ctx context.Context, call *Call) (PlannedChange, error) {
+			change := PlannedChange{Call: call}
+			switch call.Method {
+			case "AddRecord":
+				change.After = parameterRecord(call.Param)
+			case "UpdateRecord", "DeleteRecord", "SetRecordStatus":
+				before, err := api.GetRecord(ctx, &Parameter{Domain: call.Domain, ID: call.Param.ID})
+				if err != nil {
+					return change, err
+				}
+				change.Before = before
+				change.After = plannedRecord(call, before)
+			case "AddDomain", "DeleteDomain":
+				domains, err := api.ListDomains(ctx)
+				if err != nil {
+					return change, err
+				}
+				exists := slices.ContainsFunc(domains, func(d Domain) bool { return d.Name == call.Domain })
+				if call.Method == "AddDomain" && exists {
+					return change, Errorf(ErrConflict, "域名已存在: %s", call.Domain)
+				}
+				if call.Method == "DeleteDomain" && !exists {
+					return change, Errorf(ErrNotFound, "域名不存在: %s", call.Domain)
+				}
+			}
+			return change, nil
+		}
+
+		return Intercept(func(ctx context.Context, call *Call, invoke func(ctx context.Context) error) error {
+			if !call.Mutating() {
+				return invoke(ctx)
+			}
+			calls := []*Call{call}
+			if call.Method == "ApplyChanges" {
+				calls = changeCalls(call.Domain, call.Changes)
+			}
+			changes := make([]PlannedChange, 0, len(calls))
+			for _, c := range calls {
+				change, err := plan(ctx, c)
+				if err != nil {
+					if call.Method == "ApplyChanges" {
+						return fmt.Errorf("%s: %w", c, err)
+					}
+					return err
+				}
+				changes = append(changes, change)
+			}
+			for _, change := range changes {
+				report(change)
+			}
+			return nil
+		})(api)
+	}
+}
+
+// changeCalls 把批量修改拆分为单独的调用
+func changeCalls(domain string, changes []Change) []*Call {
+	methods := map[ChangeAction]string{
+		ChangeCreate: "AddRecord",
+		ChangeUpdate: "UpdateRecord",
+		ChangeDelete: "DeleteRecord",
+	}
+	calls := make([]*Call, 0, len(changes))
+	for _, c := range changes {
+		param := *c.Param
+		param.Domain = domain
+		calls = append(calls, &Call{Method: methods[c.Action], Domain: domain, Param: &param})
+	}
+	return calls
+}
+
+// parameterRecord 返回按参数添加后的记录
+func parameterRecord(p *Parameter) *Record {
+	r := &Record{
+		Domain:   p.Domain,
+		Name:     p.Name,
+		Type:     p.Type,
+		Value:    p.Value,
+		TTL:      p.TTL,
+		Line:     p.Line,
+		Priority: p.Priority,
+		Weight:   p.Weight,
+		Proxied:  p.Proxied,
+		Remark:   p.Remark,
+		SRV:      p.SRV,
+		CAA:      p.CAA,
+		MX:       p.MX,
+	}
+	r.SetData()
+	return r
+}
+
+// plannedRecord 返回 before 按调用修改后的记录，未指定的参数保持原值
+func plannedRecord(call *Call, before *Record) *Record {
+	p := call.Param
+	after := *before
+	after.Updated = ""
+	switch call.Method {
+	case "DeleteRecord":
+		return nil
+	case "SetRecordStatus":
+		after.Status = p.Status
+		return &after
+	}
+	after.Name, after.Type, after.Value = p.Name, p.Type, p.Value
+	after.Proxied = p.Proxied
+	after.SRV, after.CAA, after.MX = p.SRV, p.CAA, p.MX
+	if p.TTL > 0 {
+		after.TTL = p.TTL
+	}
+	if p.Line != "" {
+		after.Line = p.Line
+	}
+	if p.Priority > 0 {
+		after.Priority = p.Priority
+	}
+	if p.Weight > 0 || p.ZeroWeight {
+		after.Weight = p.Weight
+	}
+	if p.Remark != "" {
+		after.Remark = p.Remark
+	}
+	after.SetData()
+	return &after
+}
@@ -0,0 +1,123 @@
+package dnsapi
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// records 只实现 ListDomains 和 GetRecord 的客户端，用于 dry-run 查询修改前的记录
+type records struct {
+	flaky
+	records map[string]Record
+}
+
+func (r *records) ListDomains(ctx context.Context) ([]Domain, error) {
+	return []Domain{{Name: "example.com"}}, nil
+}
+
+func (r *records) GetRecord(ctx context.Context, param *Parameter) (*Record, error) {
+	record, ok := r.records[param.ID]
+	if !ok {
+		return nil, Errorf(ErrNotFound, "解析记录不存在: %s", param.ID)
+	}
+	return &record, nil
+}
+
+func TestDryRun(t *testing.T) {
+	var planned []PlannedChange
+	inner := &records{records: map[string]Record{
+		"1": {ID: "1", Domain: "example.com", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Status: StatusEnable},
+	}}
+	api := DryRun(func(c PlannedChange) { planned = append(planned, c) })(inner)
+
+	ctx := context.Background()
+	if err := api.AddRecord(ctx, &Parameter{Domain: "example.com", Name: "api", Type: "A", Value: "192.0.2.2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.UpdateRecord(ctx, &Parameter{Domain: "example.com", ID: "1", Name: "www", Type: "A", Value: "192.0.2.3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.SetRecordStatus(ctx, &Parameter{Domain: "example.com", ID: "1", Status: StatusDisable}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.DeleteRecord(ctx, &Parameter{Domain: "example.com", ID: "2"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除不存在的记录的错误 = %v, 应为 ErrNotFound", err)
+	}
+	d, err := api.AddDomain(ctx, "example.org")
+	if err != nil || d == nil || d.Name != "example.org" {
+		t.Errorf("AddDomain = %v, %v, 应返回只包含域名的 Domain", d, err)
+	}
+	if _, err := api.AddDomain(ctx, "example.com"); !errors.Is(err, ErrConflict) {
+		t.Errorf("添加已存在的域名的错误 = %v, 应为 ErrConflict", err)
+	}
+	if err := api.DeleteDomain(ctx, "example.net"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除不存在的域名的错误 = %v, 应为 ErrNotFound", err)
+	}
+
+	var methods []string
+	for _, c := range planned {
+		methods = append(methods, c.Call.Method)
+	}
+	if want := []string{"AddRecord", "UpdateRecord", "SetRecordStatus", "AddDomain"}; !slices.Equal(methods, want) {
+		t.Fatalf("拦截的修改 = %v, 应为 %v", methods, want)
+	}
+	if add := planned[0]; add.Before != nil || add.After == nil || add.After.Value != "192.0.2.2" {
+		t.Errorf("添加记录的修改前后 = %v, %v", add.Before, add.After)
+	}
+	if update := planned[1]; update.Before.Value != "192.0.2.1" || update.After.Value != "192.0.2.3" || update.After.TTL != 600 {
+		t.Errorf("修改记录的修改前后 = %v, %v", update.Before, update.After)
+	}
+	if status := planned[2]; status.Before.Status != StatusEnable || status.After.Status != StatusDisable {
+		t.Errorf("暂停记录的修改前后 = %v, %v", status.Before, status.After)
+	}
+	if inner.calls != 0 {
+		t.Errorf("调用次数 = %d, 不应执行任何修改", inner.calls)
+	}
+}
+
+func TestDryRunApplyChanges(t *testing.T) {
+	var planned []PlannedChange
+	inner := &records{records: map[string]Record{
+		"1": {ID: "1", Domain: "example.com", Name: "www", Type: "A", Value: "192.0.2.1"},
+	}}
+	api := DryRun(func(c PlannedChange) { planned = append(planned, c) })(recordsBatcher{inner})
+	if _, ok := api.(Batcher); !ok {
+		t.Fatal("内层客户端实现了 Batcher 时应实现 Batcher")
+	}
+
+	ctx := context.Background()
+	changes := []Change{
+		{Action: ChangeCreate, Param: &Parameter{Name: "api", Type: "A", Value: "192.0.2.2"}},
+		{Action: ChangeDelete, Param: &Parameter{ID: "1"}},
+	}
+	results, err := ApplyChanges(ctx, api, "example.com", changes)
+	if err != nil || len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("ApplyChanges = %v, %v, 应返回全部成功的结果", results, err)
+	}
+	if len(planned) != 2 || planned[1].Call.Method != "DeleteRecord" || planned[1].Before.Name != "www" || planned[1].After != nil {
+		t.Errorf("拦截的修改 = %+v", planned)
+	}
+
+	planned = nil
+	changes = append(changes, Change{Action: ChangeDelete, Param: &Parameter{ID: "2"}})
+	if _, err := ApplyChanges(ctx, api, "example.com", changes); !errors.Is(err, ErrNotFound) {
+		t.Errorf("包含不存在的记录时的错误 = %v, 应为 ErrNotFound", err)
+	}
+	if len(planned) != 0 {
+		t.Errorf("整批失败时不应输出修改: %+v", planned)
+	}
+	if inner.calls != 0 {
+		t.Errorf("调用次数 = %d, 不应执行任何修改", inner.calls)
+	}
+}
+
+// recordsBatcher 实现了 Batcher 的 records
+type recordsBatcher struct {
+	*records
+}
+
+func (r recordsBatcher) ApplyChanges(ctx context.Context, domain string, changes []Change) ([]ChangeResult, error) {
+	return nil, r.next()
+}
@@ -0,0 +1,25 @@
+package dnsapi
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ParseEndpoint 解析服务商 API 的访问地址
+//
+// endpoint 可以是主机名（可带端口），也可以是 http:// 或 https:// 开头的 URL，
+// 返回大写的协议名和主机，未指定协议时使用 HTTPS。
+func ParseEndpoint(endpoint string) (scheme, host string, err error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", "", Errorf(ErrInvalidInput, "无效的 API 地址：%s", endpoint)
+	}
+	scheme = strings.ToUpper(u.Scheme)
+	if scheme != "HTTP" && scheme != "HTTPS" {
+		return "", "", Errorf(ErrInvalidInput, "API 地址只支持 http 和 https 协议：%s", endpoint)
+	}
+	return scheme, u.Host, nil
+}
@@ -0,0 +1,113 @@
+package dnsapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 错误分类，各服务商的错误会被映射为以下错误之一，调用方通过 errors.Is 判断
+var (
+	// ErrNotFound 域名或解析记录不存在
+	ErrNotFound = errors.New("资源不存在")
+	// ErrAuthFailed 认证失败或没有权限
+	ErrAuthFailed = errors.New("认证失败")
+	// ErrRateLimited 请求过于频繁，被服务商限流
+	ErrRateLimited = errors.New("请求过于频繁")
+	// ErrConflict 资源已存在或与现有资源冲突
+	ErrConflict = errors.New("资源已存在或冲突")
+	// ErrInvalidInput 请求参数无效
+	ErrInvalidInput = errors.New("无效的参数")
+	// ErrQuotaExceeded 超出服务商的配额限制
+	ErrQuotaExceeded = errors.New("超出配额限制")
+	// ErrUnsupported 服务商不支持该操作
+	ErrUnsupported = errors.New("不支持的操作")
+	// ErrUnavailable 服务商暂时不可用，如服务端内部错误或网络错误，稍后重试可能成功
+	ErrUnavailable = errors.New("服务暂时不可用")
+	// ErrDenied 操作被本地策略拒绝，如只读模式下的修改操作
+	ErrDenied = errors.New("操作被拒绝")
+)
+
+// Error 带分类的错误，通常由服务商返回的错误转换而来
+//
+// Kind 为上面定义的错误分类之一，无法归类时为 nil；Err 为 SDK 返回的原始错误。
+type Error struct {
+	// Kind 错误分类
+	Kind error
+	// Code 服务商错误码
+	Code string
+	// Message 服务商错误信息
+	Message string
+	// Err 原始错误
+	Err error
+	// RetryAfter 服务商要求的重试等待时间，没有要求时为 0
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("%s (%s)", msg, e.Code)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// NewError 创建服务商错误
+func NewError(kind error, code, message string, err error) *Error {
+	return &Error{Kind: kind, Code: code, Message: message, Err: err}
+}
+
+// Errorf 创建指定分类的错误，用于参数校验等本地产生的错误
+func Errorf(kind error, format string, a ...any) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
+
+// KindFromHTTPStatus 根据 HTTP 状态码推断错误分类，无法推断时返回 nil
+func KindFromHTTPStatus(status int) error {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrInvalidInput
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthFailed
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// ParseRetryAfter 解析 HTTP 响应头 Retry-After，支持秒数和 HTTP 日期两种格式，无法解析时返回 0
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
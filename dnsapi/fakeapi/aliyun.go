@@ -0,0 +1,405 @@
+package fakeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// NewAliyun 启动模拟的阿里云云解析 DNS API，客户端通过 aliyun.Client.SetEndpoint 连接
+// https://help.aliyun.com/zh/dns/api-alidns-2015-01-09-overview
+func NewAliyun(t testing.TB) *Server {
+	return newServer(t, func(s *Server) http.Handler {
+		h := &aliyunHandler{Server: s}
+		s.rateLimited = func(w http.ResponseWriter) {
+			h.writeError(w, &apiError{http.StatusBadRequest, "Throttling.User", "Request was denied due to user flow control."})
+		}
+		return h
+	})
+}
+
+type aliyunHandler struct {
+	*Server
+}
+
+// aliyunActions 支持的接口
+var aliyunActions = map[string]func(h *aliyunHandler, ctx context.Context, form url.Values) (any, error){
+	"DescribeDomainRecords":    (*aliyunHandler).describeDomainRecords,
+	"DescribeDomainRecordInfo": (*aliyunHandler).describeDomainRecordInfo,
+	"AddDomainRecord":          (*aliyunHandler).addDomainRecord,
+	"UpdateDomainRecord":       (*aliyunHandler).updateDomainRecord,
+	"DeleteDomainRecord":       (*aliyunHandler).deleteDomainRecord,
+	"SetDomainRecordStatus":    (*aliyunHandler).setDomainRecordStatus,
+	"UpdateDomainRecordRemark": (*aliyunHandler).updateDomainRecordRemark,
+	"SetDNSSLBStatus":          (*aliyunHandler).setDNSSLBStatus,
+	"UpdateDNSSLBWeight":       (*aliyunHandler).updateDNSSLBWeight,
+	"DescribeSupportLines":     (*aliyunHandler).describeSupportLines,
+	"DescribeDomains":          (*aliyunHandler).describeDomains,
+	"AddDomain":                (*aliyunHandler).addDomain,
+	"DeleteDomain":             (*aliyunHandler).deleteDomain,
+}
+
+func (h *aliyunHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.writeError(w, &apiError{http.StatusBadRequest, "InvalidParameter", err.Error()})
+		return
+	}
+	if r.Form.Get("AccessKeyId") == InvalidCredential {
+		h.writeError(w, &apiError{http.StatusNotFound, "InvalidAccessKeyId.NotFound", "Specified access key is not found."})
+		return
+	}
+	action, ok := aliyunActions[r.Form.Get("Action")]
+	if !ok {
+		h.writeError(w, &apiError{http.StatusNotFound, "InvalidAction.NotFound", "Specified api is not found, please check your url and method."})
+		return
+	}
+	response, err := action(h, r.Context(), r.Form)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writeError 按照阿里云的格式返回错误，未指定错误码的错误按分类转换
+func (h *aliyunHandler) writeError(w http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = &apiError{http.StatusBadRequest, "InvalidParameter", errorMessage(err)}
+		switch {
+		case errors.Is(err, dnsapi.ErrNotFound):
+			e.code = "InvalidDomainName.NoExist"
+		case errors.Is(err, dnsapi.ErrConflict):
+			e.code = "DomainRecordDuplicate"
+		case !errors.Is(err, dnsapi.ErrInvalidInput) && !errors.Is(err, dnsapi.ErrUnsupported):
+			e.status, e.code = http.StatusInternalServerError, "InternalError"
+		}
+	}
+	writeJSON(w, e.status, map[string]string{
+		"Code":      e.code,
+		"Message":   e.message,
+		"RequestId": requestID(),
+	})
+}
+
+// record 返回指定 ID 的记录，阿里云对不存在的记录返回 DomainRecordNotBelongToUser
+func (h *aliyunHandler) record(ctx context.Context, id string) (*dnsapi.Record, error) {
+	record, err := h.Server.record(ctx, id)
+	if errors.Is(err, dnsapi.ErrNotFound) {
+		return nil, &apiError{http.StatusBadRequest, "DomainRecordNotBelongToUser", "The DNS record does not belong to you."}
+	}
+	return record, err
+}
+
+func (h *aliyunHandler) describeDomainRecords(ctx context.Context, form url.Values) (any, error) {
+	param := dnsapi.CreateParameter(form.Get("DomainName"))
+	param.Name = form.Get("RRKeyWord")
+	param.Type = form.Get("Type")
+	param.Line = form.Get("Line")
+	param.Value = form.Get("ValueKeyWord")
+	records, err := h.Backend.ListRecords(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	pageNumber, pageSize := formInt(form, "PageNumber", 1), formInt(form, "PageSize", 20)
+	if pageNumber < 1 || pageSize < 1 || pageSize > 500 {
+		return nil, &apiError{http.StatusBadRequest, "InvalidParameter", "PageNumber or PageSize is invalid."}
+	}
+	response := &aliyunRecordsResponse{
+		RequestId:  requestID(),
+		TotalCount: int64(len(records)),
+		PageNumber: int64(pageNumber),
+		PageSize:   int64(pageSize),
+	}
+	response.DomainRecords.Record = make([]aliyunRecord, 0)
+	for _, r := range page(records, (pageNumber-1)*pageSize, pageSize) {
+		record := aliyunRecord{Record: alidns.Record{
+			RecordId:   r.ID,
+			DomainName: r.Domain,
+			RR:         r.Name,
+			Type:       r.Type,
+			Value:      r.Value,
+			TTL:        int64(r.TTL),
+			Line:       r.Line,
+			Priority:   aliyunPriority(&r),
+			Weight:     r.Weight,
+			Status:     r.Status,
+			Remark:     r.Remark,
+		}}
+		if updated, err := time.ParseInLocation(time.DateTime, r.Updated, time.Local); err == nil {
+			record.UpdateTimestamp = updated.UnixMilli()
+		}
+		response.DomainRecords.Record = append(response.DomainRecords.Record, record)
+	}
+	return response, nil
+}
+
+// aliyunRecordsResponse DescribeDomainRecords 的响应，SDK 的 alidns.Record 中没有 UpdateTimestamp 字段
+type aliyunRecordsResponse struct {
+	RequestId     string
+	TotalCount    int64
+	PageNumber    int64
+	PageSize      int64
+	DomainRecords struct {
+		Record []aliyunRecord
+	}
+}
+
+type aliyunRecord struct {
+	alidns.Record
+	UpdateTimestamp int64
+}
+
+func (h *aliyunHandler) describeDomainRecordInfo(ctx context.Context, form url.Values) (any, error) {
+	r, err := h.record(ctx, form.Get("RecordId"))
+	if err != nil {
+		return nil, err
+	}
+	return &alidns.DescribeDomainRecordInfoResponse{
+		RequestId:  requestID(),
+		RecordId:   r.ID,
+		DomainName: r.Domain,
+		RR:         r.Name,
+		Type:       r.Type,
+		Value:      r.Value,
+		TTL:        int64(r.TTL),
+		Line:       r.Line,
+		Priority:   aliyunPriority(r),
+		Status:     r.Status,
+		Remark:     r.Remark,
+	}, nil
+}
+
+func (h *aliyunHandler) addDomainRecord(ctx context.Context, form url.Values) (any, error) {
+	param := dnsapi.CreateParameter(form.Get("DomainName"))
+	aliyunRecordParameter(param, form)
+	if err := h.Backend.AddRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	records, err := h.Backend.ListRecords(ctx, dnsapi.CreateParameter(param.Domain))
+	if err != nil {
+		return nil, err
+	}
+	return &alidns.AddDomainRecordResponse{RequestId: requestID(), RecordId: records[len(records)-1].ID}, nil
+}
+
+func (h *aliyunHandler) updateDomainRecord(ctx context.Context, form url.Values) (any, error) {
+	r, err := h.record(ctx, form.Get("RecordId"))
+	if err != nil {
+		return nil, err
+	}
+	// 与阿里云一致，未指定的线路恢复为默认线路，备注和权重保持不变
+	param := recordParameter(r)
+	param.Line = ""
+	aliyunRecordParameter(param, form)
+	if param.Line == "" {
+		param.Line = "default"
+	}
+	if err := h.Backend.UpdateRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	return &alidns.UpdateDomainRecordResponse{RequestId: requestID(), RecordId: r.ID}, nil
+}
+
+func (h *aliyunHandler) deleteDomainRecord(ctx context.Context, form url.Values) (any, error) {
+	r, err := h.record(ctx, form.Get("RecordId"))
+	if err != nil {
+		return nil, err
+	}
+	if err := h.Backend.DeleteRecord(ctx, recordParameter(r)); err != nil {
+		return nil, err
+	}
+	return &alidns.DeleteDomainRecordResponse{RequestId: requestID(), RecordId: r.ID}, nil
+}
+
+func (h *aliyunHandler) setDomainRecordStatus(ctx context.Context, form url.Values) (any, error) {
+	r, err := h.record(ctx, form.Get("RecordId"))
+	if err != nil {
+		return nil, err
+	}
+	param := recordParameter(r)
+	switch status := form.Get("Status"); status {
+	case "Enable":
+		param.Status = dnsapi.StatusEnable
+	case "Disable":
+		param.Status = dnsapi.StatusDisable
+	default:
+		return nil, &apiError{http.StatusBadRequest, "InvalidStatus", "The specified status is invalid: " + status}
+	}
+	if err := h.Backend.SetRecordStatus(ctx, param); err != nil {
+		return nil, err
+	}
+	return &alidns.SetDomainRecordStatusResponse{RequestId: requestID(), RecordId: r.ID, Status: form.Get("Status")}, nil
+}
+
+func (h *aliyunHandler) updateDomainRecordRemark(ctx context.Context, form url.Values) (any, error) {
+	r, err := h.record(ctx, form.Get("RecordId"))
+	if err != nil {
+		return nil, err
+	}
+	param := recordParameter(r)
+	param.Remark = form.Get("Remark")
+	if err := h.Backend.UpdateRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	return &alidns.UpdateDomainRecordRemarkResponse{RequestId: requestID()}, nil
+}
+
+// setDNSSLBStatus 权重配置保存在记录中，这里只检查域名和记录是否存在
+func (h *aliyunHandler) setDNSSLBStatus(ctx context.Context, form url.Values) (any, error) {
+	domain := form.Get("DomainName")
+	records, err := h.Backend.ListRecords(ctx, dnsapi.CreateParameter(domain))
+	if err != nil {
+		return nil, err
+	}
+	var count int64
+	for _, r := range records {
+		if fqdn(r.Name, domain) == form.Get("SubDomain") && (form.Get("Type") == "" || r.Type == form.Get("Type")) {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil, &apiError{http.StatusBadRequest, "DomainRecordNotBelongToUser", "The DNS record does not belong to you."}
+	}
+	return &alidns.SetDNSSLBStatusResponse{RequestId: requestID(), RecordCount: count, Open: form.Get("Open") != "false"}, nil
+}
+
+func (h *aliyunHandler) updateDNSSLBWeight(ctx context.Context, form url.Values) (any, error) {
+	r, err := h.record(ctx, form.Get("RecordId"))
+	if err != nil {
+		return nil, err
+	}
+	param := recordParameter(r)
+	param.Weight = formInt(form, "Weight", 0)
+	if param.Weight < 1 {
+		return nil, &apiError{http.StatusBadRequest, "InvalidWeight", "The specified weight is invalid."}
+	}
+	if err := h.Backend.UpdateRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	return &alidns.UpdateDNSSLBWeightResponse{RequestId: requestID(), RecordId: r.ID, Weight: param.Weight}, nil
+}
+
+func (h *aliyunHandler) describeSupportLines(ctx context.Context, form url.Values) (any, error) {
+	lines, err := h.Backend.ListLines(ctx, form.Get("DomainName"))
+	if err != nil {
+		return nil, err
+	}
+	response := &alidns.DescribeSupportLinesResponse{RequestId: requestID()}
+	for _, l := range lines {
+		response.RecordLines.RecordLine = append(response.RecordLines.RecordLine, alidns.RecordLine{
+			LineCode:        l.Code,
+			LineName:        l.Name,
+			LineDisplayName: l.Name,
+			FatherCode:      l.Parent,
+		})
+	}
+	return response, nil
+}
+
+func (h *aliyunHandler) describeDomains(ctx context.Context, form url.Values) (any, error) {
+	domains, err := h.Backend.ListDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pageNumber, pageSize := formInt(form, "PageNumber", 1), formInt(form, "PageSize", 20)
+	if pageNumber < 1 || pageSize < 1 || pageSize > 100 {
+		return nil, &apiError{http.StatusBadRequest, "InvalidParameter", "PageNumber or PageSize is invalid."}
+	}
+	response := &alidns.DescribeDomainsResponse{
+		RequestId:  requestID(),
+		TotalCount: int64(len(domains)),
+		PageNumber: int64(pageNumber),
+		PageSize:   int64(pageSize),
+	}
+	response.Domains.Domain = make([]alidns.DomainInDescribeDomains, 0)
+	for _, d := range page(domains, (pageNumber-1)*pageSize, pageSize) {
+		created, _ := time.ParseInLocation(time.DateTime, d.Created, time.Local)
+		domain := alidns.DomainInDescribeDomains{
+			DomainId:        d.ID,
+			DomainName:      d.Name,
+			RecordCount:     int64(d.RecordCount),
+			VersionName:     "免费版",
+			CreateTimestamp: created.UnixMilli(),
+		}
+		domain.DnsServers.DnsServer = d.NameServers
+		response.Domains.Domain = append(response.Domains.Domain, domain)
+	}
+	return response, nil
+}
+
+func (h *aliyunHandler) addDomain(ctx context.Context, form url.Values) (any, error) {
+	d, err := h.Backend.AddDomain(ctx, form.Get("DomainName"))
+	if errors.Is(err, dnsapi.ErrConflict) {
+		return nil, &apiError{http.StatusBadRequest, "DomainAlreadyExist", "The domain name already exists."}
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := &alidns.AddDomainResponse{RequestId: requestID(), DomainId: d.ID, DomainName: d.Name}
+	response.DnsServers.DnsServer = d.NameServers
+	return response, nil
+}
+
+func (h *aliyunHandler) deleteDomain(ctx context.Context, form url.Values) (any, error) {
+	if err := h.Backend.DeleteDomain(ctx, form.Get("DomainName")); err != nil {
+		return nil, err
+	}
+	return &alidns.DeleteDomainResponse{RequestId: requestID(), DomainName: form.Get("DomainName")}, nil
+}
+
+// aliyunRecordParameter 读取添加和修改记录接口的参数，未传的参数保持 param 中原来的值
+func aliyunRecordParameter(param *dnsapi.Parameter, form url.Values) {
+	for key, field := range map[string]*string{"RR": &param.Name, "Type": &param.Type, "Value": &param.Value, "Line": &param.Line} {
+		if form.Has(key) {
+			*field = form.Get(key)
+		}
+	}
+	param.TTL = formInt(form, "TTL", param.TTL)
+	param.Priority = formInt(form, "Priority", param.Priority)
+}
+
+// aliyunPriority 阿里云只返回 MX 记录的优先级
+func aliyunPriority(r *dnsapi.Record) int64 {
+	if r.Type == "MX" {
+		return int64(r.Priority)
+	}
+	return 0
+}
+
+// formInt 读取整数参数，参数不存在或无效时返回 def
+func formInt(form url.Values, key string, def int) int {
+	n, err := strconv.Atoi(form.Get(key))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// page 返回 items 中从 offset 开始的最多 limit 个元素
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// requestID 返回请求 ID，模拟服务中仅用于填充响应
+func requestID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 16)
+}
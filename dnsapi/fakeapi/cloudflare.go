@@ -0,0 +1,580 @@
+package fakeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+// CloudflareAccountID 模拟的 Cloudflare 账号下唯一的账户 ID
+const CloudflareAccountID = "fakeapi-account"
+
+// cloudflareBatchLimit 批量接口单次请求的最大修改数，与免费套餐相同
+const cloudflareBatchLimit = 200
+
+// NewCloudflare 启动模拟的 Cloudflare v4 API，客户端通过 cloudflare.Client.SetEndpoint 连接，
+// 接口路径不包含 /client/v4 前缀
+// https://developers.cloudflare.com/api/
+func NewCloudflare(t testing.TB) *Server {
+	return newServer(t, func(s *Server) http.Handler {
+		h := &cloudflareHandler{Server: s, extras: make(map[string]cloudflareExtra)}
+		s.rateLimited = func(w http.ResponseWriter) {
+			writeCloudflareError(w, &apiError{http.StatusTooManyRequests, "971", "Please wait and consider throttling your request speed"})
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /accounts", h.handle(h.listAccounts))
+		mux.HandleFunc("GET /zones", h.handle(h.listZones))
+		mux.HandleFunc("POST /zones", h.handle(h.createZone))
+		mux.HandleFunc("DELETE /zones/{zone}", h.handle(h.deleteZone))
+		mux.HandleFunc("GET /zones/{zone}/dns_records", h.handle(h.listRecords))
+		mux.HandleFunc("POST /zones/{zone}/dns_records", h.handle(h.createRecord))
+		mux.HandleFunc("POST /zones/{zone}/dns_records/batch", h.handle(h.batchRecords))
+		mux.HandleFunc("GET /zones/{zone}/dns_records/{id}", h.handle(h.getRecord))
+		mux.HandleFunc("PUT /zones/{zone}/dns_records/{id}", h.handle(h.updateRecord))
+		mux.HandleFunc("DELETE /zones/{zone}/dns_records/{id}", h.handle(h.deleteRecord))
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			writeCloudflareError(w, &apiError{http.StatusNotFound, "7000", "No route for that URI"})
+		})
+		return mux
+	})
+}
+
+type cloudflareHandler struct {
+	*Server
+	// extras 本地服务商不支持的记录字段，按记录 ID 保存
+	extras map[string]cloudflareExtra
+}
+
+// cloudflareExtra 本地服务商无法保存的 Cloudflare 记录字段
+type cloudflareExtra struct {
+	// TTL 小于本地服务商最小值的 TTL，1 表示自动
+	TTL     int
+	Proxied bool
+	// Comment 通过接口设置的备注，与真实接口一样，覆盖记录时没有 comment 会清空备注
+	Comment string
+}
+
+// cloudflareRecord 添加和修改记录时的请求参数
+type cloudflareRecord struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Content  string   `json:"content"`
+	TTL      int      `json:"ttl"`
+	Proxied  bool     `json:"proxied"`
+	Priority *float64 `json:"priority"`
+	Comment  string   `json:"comment"`
+	Data     *struct {
+		Priority float64 `json:"priority"`
+		Weight   float64 `json:"weight"`
+		Port     float64 `json:"port"`
+		Target   string  `json:"target"`
+		Flags    float64 `json:"flags"`
+		Tag      string  `json:"tag"`
+		Value    string  `json:"value"`
+	} `json:"data"`
+}
+
+// cloudflareResult 接口的返回结果，info 不为空时返回分页信息
+type cloudflareResult struct {
+	result any
+	info   map[string]int
+}
+
+// handle 检查认证信息并按照 Cloudflare 的格式返回结果
+func (h *cloudflareHandler) handle(fn func(r *http.Request) (*cloudflareResult, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == InvalidCredential || r.Header.Get("X-Auth-Key") == InvalidCredential {
+			writeCloudflareError(w, &apiError{http.StatusForbidden, "10000", "Authentication error"})
+			return
+		}
+		result, err := fn(r)
+		if err != nil {
+			writeCloudflareError(w, err)
+			return
+		}
+		response := map[string]any{
+			"success":  true,
+			"errors":   []any{},
+			"messages": []any{},
+			"result":   result.result,
+		}
+		if result.info != nil {
+			response["result_info"] = result.info
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// writeCloudflareError 按照 Cloudflare 的格式返回错误，未指定错误码的错误按分类转换
+func writeCloudflareError(w http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = &apiError{http.StatusBadRequest, "1004", "DNS Validation Error: " + errorMessage(err)}
+		switch {
+		case errors.Is(err, dnsapi.ErrNotFound):
+			e.status, e.code, e.message = http.StatusNotFound, "81044", "Record does not exist."
+		case errors.Is(err, dnsapi.ErrConflict) && strings.Contains(err.Error(), "CNAME"):
+			e.code, e.message = "81053", "An A, AAAA, or CNAME record with that host already exists."
+		case errors.Is(err, dnsapi.ErrConflict):
+			e.code, e.message = "81058", "An identical record already exists."
+		case !errors.Is(err, dnsapi.ErrInvalidInput) && !errors.Is(err, dnsapi.ErrUnsupported):
+			e.status, e.code, e.message = http.StatusInternalServerError, "10001", errorMessage(err)
+		}
+	}
+	code, _ := strconv.Atoi(e.code)
+	writeJSON(w, e.status, map[string]any{
+		"success":  false,
+		"errors":   []any{map[string]any{"code": code, "message": e.message}},
+		"messages": []any{},
+		"result":   nil,
+	})
+}
+
+// zone 返回请求路径中的域名
+func (h *cloudflareHandler) zone(r *http.Request) (*dnsapi.Domain, error) {
+	id := r.PathValue("zone")
+	zone, err := h.domainByID(r.Context(), id)
+	if errors.Is(err, dnsapi.ErrNotFound) {
+		return nil, &apiError{http.StatusNotFound, "7003", fmt.Sprintf("Could not route to /zones/%s, perhaps your object identifier is invalid?", id)}
+	}
+	return zone, err
+}
+
+func (h *cloudflareHandler) listAccounts(r *http.Request) (*cloudflareResult, error) {
+	accounts := []any{map[string]any{"id": CloudflareAccountID, "name": "fakeapi", "type": "standard"}}
+	return &cloudflareResult{result: accounts, info: pageInfo(1, 20, 1, 1)}, nil
+}
+
+func (h *cloudflareHandler) listZones(r *http.Request) (*cloudflareResult, error) {
+	domains, err := h.Backend.ListDomains(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	query := r.URL.Query()
+	if name := query.Get("name"); name != "" {
+		domains = filter(domains, func(d dnsapi.Domain) bool { return d.Name == name })
+	}
+	pageNumber, perPage, err := pagination(query, 20, 50)
+	if err != nil {
+		return nil, err
+	}
+	zones := make([]any, 0)
+	for _, d := range page(domains, (pageNumber-1)*perPage, perPage) {
+		zones = append(zones, cloudflareZone(&d))
+	}
+	return &cloudflareResult{result: zones, info: pageInfo(pageNumber, perPage, len(zones), len(domains))}, nil
+}
+
+func (h *cloudflareHandler) createZone(r *http.Request) (*cloudflareResult, error) {
+	var body struct {
+		Account struct {
+			ID string `json:"id"`
+		} `json:"account"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, &apiError{http.StatusBadRequest, "1004", err.Error()}
+	}
+	if body.Account.ID != CloudflareAccountID {
+		return nil, &apiError{http.StatusForbidden, "1068", "Permission denied."}
+	}
+	d, err := h.Backend.AddDomain(r.Context(), body.Name)
+	if errors.Is(err, dnsapi.ErrConflict) {
+		return nil, &apiError{http.StatusBadRequest, "1061", body.Name + " already exists"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cloudflareResult{result: cloudflareZone(d)}, nil
+}
+
+func (h *cloudflareHandler) deleteZone(r *http.Request) (*cloudflareResult, error) {
+	zone, err := h.zone(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.Backend.DeleteDomain(r.Context(), zone.Name); err != nil {
+		return nil, err
+	}
+	return &cloudflareResult{result: map[string]string{"id": zone.ID}}, nil
+}
+
+func (h *cloudflareHandler) listRecords(r *http.Request) (*cloudflareResult, error) {
+	zone, err := h.zone(r)
+	if err != nil {
+		return nil, err
+	}
+	all, err := h.Backend.ListRecords(r.Context(), dnsapi.CreateParameter(zone.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	// 参数为空时不过滤，与 SDK 对空字符串参数的处理保持一致
+	query := r.URL.Query()
+	records := filter(all, func(record dnsapi.Record) bool {
+		name, content := fqdn(record.Name, zone.Name), h.content(&record)
+		return match(query, "name", name, func(a, b string) bool { return a == b }) &&
+			match(query, "name.exact", name, func(a, b string) bool { return a == b }) &&
+			match(query, "name.startswith", name, strings.HasPrefix) &&
+			match(query, "name.contains", name, strings.Contains) &&
+			match(query, "type", record.Type, func(a, b string) bool { return a == b }) &&
+			match(query, "content", content, func(a, b string) bool { return a == b }) &&
+			match(query, "content.exact", content, func(a, b string) bool { return a == b }) &&
+			match(query, "content.startswith", content, strings.HasPrefix) &&
+			match(query, "content.contains", content, strings.Contains)
+	})
+
+	pageNumber, perPage, err := pagination(query, 100, 5000)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, 0)
+	for _, record := range page(records, (pageNumber-1)*perPage, perPage) {
+		result = append(result, h.cloudflareRecord(zone, &record))
+	}
+	return &cloudflareResult{result: result, info: pageInfo(pageNumber, perPage, len(result), len(records))}, nil
+}
+
+func (h *cloudflareHandler) getRecord(r *http.Request) (*cloudflareResult, error) {
+	zone, err := h.zone(r)
+	if err != nil {
+		return nil, err
+	}
+	record, err := h.Backend.GetRecord(r.Context(), &dnsapi.Parameter{Domain: zone.Name, ID: r.PathValue("id")})
+	if err != nil {
+		return nil, err
+	}
+	return &cloudflareResult{result: h.cloudflareRecord(zone, record)}, nil
+}
+
+func (h *cloudflareHandler) createRecord(r *http.Request) (*cloudflareResult, error) {
+	zone, err := h.zone(r)
+	if err != nil {
+		return nil, err
+	}
+	var body cloudflareRecord
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, &apiError{http.StatusBadRequest, "1004", err.Error()}
+	}
+	record, err := h.addRecord(r.Context(), zone, &body)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudflareResult{result: record}, nil
+}
+
+func (h *cloudflareHandler) updateRecord(r *http.Request) (*cloudflareResult, error) {
+	zone, err := h.zone(r)
+	if err != nil {
+		return nil, err
+	}
+	var body cloudflareRecord
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, &apiError{http.StatusBadRequest, "1004", err.Error()}
+	}
+	body.ID = r.PathValue("id")
+	record, err := h.putRecord(r.Context(), zone, &body)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudflareResult{result: record}, nil
+}
+
+func (h *cloudflareHandler) deleteRecord(r *http.Request) (*cloudflareResult, error) {
+	zone, err := h.zone(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.removeRecord(r.Context(), zone, r.PathValue("id")); err != nil {
+		return nil, err
+	}
+	return &cloudflareResult{result: map[string]string{"id": r.PathValue("id")}}, nil
+}
+
+// batchRecords 依次执行删除、修改和添加，任意一项失败时回滚所有修改
+func (h *cloudflareHandler) batchRecords(r *http.Request) (*cloudflareResult, error) {
+	zone, err := h.zone(r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Deletes []struct {
+			ID string `json:"id"`
+		} `json:"deletes"`
+		Puts  []cloudflareRecord `json:"puts"`
+		Posts []cloudflareRecord `json:"posts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, &apiError{http.StatusBadRequest, "1004", err.Error()}
+	}
+	if n := len(body.Deletes) + len(body.Puts) + len(body.Posts); n > cloudflareBatchLimit {
+		return nil, &apiError{http.StatusBadRequest, "81058", fmt.Sprintf("Too many changes in batch: %d, limit is %d", n, cloudflareBatchLimit)}
+	}
+
+	result := map[string][]any{"deletes": {}, "patches": {}, "puts": {}, "posts": {}}
+	extras := maps.Clone(h.extras)
+	err = h.atomic(func() error {
+		ctx := r.Context()
+		for _, d := range body.Deletes {
+			record, err := h.Backend.GetRecord(ctx, &dnsapi.Parameter{Domain: zone.Name, ID: d.ID})
+			if err != nil {
+				return err
+			}
+			result["deletes"] = append(result["deletes"], h.cloudflareRecord(zone, record))
+			if err := h.removeRecord(ctx, zone, d.ID); err != nil {
+				return err
+			}
+		}
+		for i := range body.Puts {
+			record, err := h.putRecord(ctx, zone, &body.Puts[i])
+			if err != nil {
+				return err
+			}
+			result["puts"] = append(result["puts"], record)
+		}
+		for i := range body.Posts {
+			record, err := h.addRecord(ctx, zone, &body.Posts[i])
+			if err != nil {
+				return err
+			}
+			result["posts"] = append(result["posts"], record)
+		}
+		return nil
+	})
+	if err != nil {
+		h.extras = extras
+		return nil, err
+	}
+	return &cloudflareResult{result: result}, nil
+}
+
+// addRecord 添加记录，返回接口中的记录格式
+func (h *cloudflareHandler) addRecord(ctx context.Context, zone *dnsapi.Domain, body *cloudflareRecord) (map[string]any, error) {
+	param, err := recordFromCloudflare(zone, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.Backend.AddRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	records, err := h.Backend.ListRecords(ctx, dnsapi.CreateParameter(zone.Name))
+	if err != nil {
+		return nil, err
+	}
+	record := records[len(records)-1]
+	h.setExtra(record.ID, body)
+	return h.cloudflareRecord(zone, &record), nil
+}
+
+// putRecord 覆盖记录，返回接口中的记录格式
+func (h *cloudflareHandler) putRecord(ctx context.Context, zone *dnsapi.Domain, body *cloudflareRecord) (map[string]any, error) {
+	param, err := recordFromCloudflare(zone, body)
+	if err != nil {
+		return nil, err
+	}
+	param.ID = body.ID
+	if err := h.Backend.UpdateRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	record, err := h.Backend.GetRecord(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	h.setExtra(record.ID, body)
+	return h.cloudflareRecord(zone, record), nil
+}
+
+func (h *cloudflareHandler) removeRecord(ctx context.Context, zone *dnsapi.Domain, id string) error {
+	if err := h.Backend.DeleteRecord(ctx, &dnsapi.Parameter{Domain: zone.Name, ID: id}); err != nil {
+		return err
+	}
+	delete(h.extras, id)
+	return nil
+}
+
+func (h *cloudflareHandler) setExtra(id string, body *cloudflareRecord) {
+	extra := cloudflareExtra{Proxied: body.Proxied, Comment: body.Comment}
+	if body.TTL < 600 {
+		extra.TTL = body.TTL
+	}
+	h.extras[id] = extra
+}
+
+// content 返回 Cloudflare 格式的记录内容，SRV 记录不包含优先级，MX 记录不包含优先级
+func (h *cloudflareHandler) content(r *dnsapi.Record) string {
+	if r.SRV != nil {
+		return fmt.Sprintf("%d %d %s", r.SRV.Weight, r.SRV.Port, r.SRV.Target)
+	}
+	return r.Value
+}
+
+// cloudflareRecord 返回接口中的记录格式
+func (h *cloudflareHandler) cloudflareRecord(zone *dnsapi.Domain, r *dnsapi.Record) map[string]any {
+	updated := timestamp(r.Updated)
+	extra, ok := h.extras[r.ID]
+	comment := r.Remark
+	if ok {
+		comment = extra.Comment
+	}
+	ttl := r.TTL
+	if extra.TTL > 0 {
+		ttl = extra.TTL
+	}
+	record := map[string]any{
+		"id":          r.ID,
+		"zone_id":     zone.ID,
+		"zone_name":   zone.Name,
+		"name":        fqdn(r.Name, zone.Name),
+		"type":        r.Type,
+		"content":     h.content(r),
+		"ttl":         ttl,
+		"proxied":     extra.Proxied,
+		"proxiable":   r.Type == "A" || r.Type == "AAAA" || r.Type == "CNAME",
+		"comment":     comment,
+		"tags":        []string{},
+		"meta":        map[string]any{},
+		"settings":    map[string]any{},
+		"created_on":  updated,
+		"modified_on": updated,
+	}
+	switch {
+	case r.MX != nil:
+		record["priority"] = r.MX.Preference
+	case r.SRV != nil:
+		record["priority"] = r.SRV.Priority
+		record["data"] = map[string]any{"priority": r.SRV.Priority, "weight": r.SRV.Weight, "port": r.SRV.Port, "target": r.SRV.Target}
+	case r.CAA != nil:
+		record["data"] = map[string]any{"flags": r.CAA.Flags, "tag": r.CAA.Tag, "value": r.CAA.Value}
+	}
+	return record
+}
+
+// recordFromCloudflare 将接口的记录参数转换为 dnsapi.Parameter
+func recordFromCloudflare(zone *dnsapi.Domain, body *cloudflareRecord) (*dnsapi.Parameter, error) {
+	param := &dnsapi.Parameter{
+		Domain: zone.Name,
+		Name:   relativeName(body.Name, zone.Name),
+		Type:   body.Type,
+		Value:  body.Content,
+		Remark: body.Comment,
+	}
+	// 本地服务商不支持小于 600 的 TTL，使用默认值保存，返回时使用 extras 中的值
+	switch {
+	case body.TTL == 0:
+		body.TTL = 1 // 未指定时为自动
+	case body.TTL >= 600:
+		param.TTL = body.TTL
+	case body.TTL != 1 && body.TTL < 60:
+		return nil, &apiError{http.StatusBadRequest, "9021", "Invalid TTL. Must be between 60 and 86400, or 1 for automatic."}
+	}
+	if body.Priority != nil {
+		param.Priority = int(*body.Priority)
+	}
+	switch body.Type {
+	case "SRV", "CAA":
+		if body.Data == nil {
+			return nil, &apiError{http.StatusBadRequest, "9101", body.Type + " records must have a data field."}
+		}
+		if body.Type == "SRV" {
+			param.SRV = &dnsapi.SRV{Priority: int(body.Data.Priority), Weight: int(body.Data.Weight), Port: int(body.Data.Port), Target: body.Data.Target}
+		} else {
+			param.CAA = &dnsapi.CAA{Flags: int(body.Data.Flags), Tag: body.Data.Tag, Value: body.Data.Value}
+		}
+		param.Value = ""
+	}
+	return param, nil
+}
+
+func cloudflareZone(d *dnsapi.Domain) map[string]any {
+	created := timestamp(d.Created)
+	return map[string]any{
+		"id":                    d.ID,
+		"name":                  d.Name,
+		"status":                "active",
+		"paused":                false,
+		"type":                  "full",
+		"development_mode":      0,
+		"name_servers":          d.NameServers,
+		"original_name_servers": []string{},
+		"created_on":            created,
+		"modified_on":           created,
+		"activated_on":          created,
+		"account":               map[string]string{"id": CloudflareAccountID, "name": "fakeapi"},
+		"plan":                  map[string]any{"id": "free", "name": "Free Website", "price": 0, "currency": "USD"},
+		"meta":                  map[string]any{},
+		"owner":                 map[string]any{},
+		"permissions":           []string{},
+	}
+}
+
+// fqdn 返回主机记录对应的完整域名
+func fqdn(name, domain string) string {
+	if name == "@" {
+		return domain
+	}
+	return name + "." + domain
+}
+
+// relativeName 返回完整域名对应的主机记录，Cloudflare 的接口也接受相对名称和 @
+func relativeName(name, domain string) string {
+	if name == domain || name == "" {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+// match 判断 value 是否满足查询参数 key 的条件，参数为空时不过滤
+func match(query url.Values, key, value string, fn func(value, want string) bool) bool {
+	want := query.Get(key)
+	return want == "" || fn(value, want)
+}
+
+// pagination 读取分页参数
+func pagination(query url.Values, defPerPage, maxPerPage int) (pageNumber, perPage int, err error) {
+	pageNumber, perPage = formInt(query, "page", 1), formInt(query, "per_page", defPerPage)
+	if pageNumber < 1 || perPage < 1 || perPage > maxPerPage {
+		return 0, 0, &apiError{http.StatusBadRequest, "1004", fmt.Sprintf("Invalid page or per_page, per_page must be between 1 and %d", maxPerPage)}
+	}
+	return pageNumber, perPage, nil
+}
+
+func pageInfo(pageNumber, perPage, count, total int) map[string]int {
+	return map[string]int{
+		"page":        pageNumber,
+		"per_page":    perPage,
+		"count":       count,
+		"total_count": total,
+		"total_pages": (total + perPage - 1) / perPage,
+	}
+}
+
+// timestamp 将本地服务商的时间转换为 RFC 3339 格式
+func timestamp(s string) string {
+	t, err := time.ParseInLocation(time.DateTime, s, time.Local)
+	if err != nil {
+		t = time.Now()
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func filter[T any](items []T, fn func(T) bool) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if fn(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
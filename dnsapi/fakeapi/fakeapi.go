@@ -0,0 +1,174 @@
+// Package fakeapi 提供阿里云云解析 DNS、腾讯云 DNSPod 和 Cloudflare API 的本地模拟服务，
+// 用于在没有云服务商账号的情况下端到端地测试客户端和命令行。
+//
+// 模拟服务基于 httptest.Server，数据保存在 local 服务商的 JSON 文件中，
+// 参数校验规则与 local 服务商一致，错误按照各服务商的格式和错误码返回：
+//
+//	srv := fakeapi.NewAliyun(t)
+//	srv.Backend.AddDomain(ctx, "example.com")
+//	client, _ := aliyun.NewClient("id", "secret", "", srv.URL)
+package fakeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/local"
+)
+
+// InvalidCredential 使用此值作为 AccessKey ID、SecretId 或 API Token 时，模拟服务返回认证失败
+const InvalidCredential = "invalid"
+
+// Server 模拟的服务商 API
+type Server struct {
+	*httptest.Server
+	// Backend 保存数据的本地服务商，可以用来准备测试数据和检查请求的结果
+	Backend *local.Client
+
+	path string
+	// mu 保证请求串行执行，批量接口失败时可以整体回滚
+	mu sync.Mutex
+	// throttled 剩余需要返回限流错误的请求数
+	throttled int
+	// rateLimited 按照服务商的格式返回限流错误
+	rateLimited func(w http.ResponseWriter)
+}
+
+// newServer 启动模拟服务，测试结束时自动关闭
+func newServer(t testing.TB, newHandler func(s *Server) http.Handler) *Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fakeapi.json")
+	backend, err := local.NewClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{Backend: backend, path: path}
+	handler := newHandler(s)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.throttled > 0 {
+			s.throttled--
+			s.rateLimited(w)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Throttle 使接下来的 n 个请求返回服务商的限流错误，用于测试重试
+func (s *Server) Throttle(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttled = n
+}
+
+// apiError 按照服务商的错误码返回的错误
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+// errorMessage 返回错误信息，不包含错误分类
+func errorMessage(err error) string {
+	var e *dnsapi.Error
+	if errors.As(err, &e) && e.Message != "" {
+		return e.Message
+	}
+	return err.Error()
+}
+
+// atomic 执行 fn，fn 返回错误时把数据恢复到执行前的状态
+func (s *Server) atomic(fn func() error) error {
+	data, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := fn(); err != nil {
+		if data == nil {
+			os.Remove(s.path)
+		} else {
+			os.WriteFile(s.path, data, 0o644)
+		}
+		return err
+	}
+	return nil
+}
+
+// domain 返回指定名称的域名，不存在时返回 ErrNotFound
+func (s *Server) domain(ctx context.Context, name string) (*dnsapi.Domain, error) {
+	domains, err := s.Backend.ListDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		if d.Name == name {
+			return &d, nil
+		}
+	}
+	return nil, dnsapi.Errorf(dnsapi.ErrNotFound, "域名不存在: %s", name)
+}
+
+// domainByID 返回指定 ID 的域名，不存在时返回 ErrNotFound
+func (s *Server) domainByID(ctx context.Context, id string) (*dnsapi.Domain, error) {
+	domains, err := s.Backend.ListDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, dnsapi.Errorf(dnsapi.ErrNotFound, "域名不存在: %s", id)
+}
+
+// record 在所有域名中查找指定 ID 的解析记录，用于只传记录 ID 的接口
+func (s *Server) record(ctx context.Context, id string) (*dnsapi.Record, error) {
+	domains, err := s.Backend.ListDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		records, err := s.Backend.ListRecords(ctx, dnsapi.CreateParameter(d.Name))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			if r.ID == id {
+				return &r, nil
+			}
+		}
+	}
+	return nil, dnsapi.Errorf(dnsapi.ErrNotFound, "解析记录不存在: %s", id)
+}
+
+// recordParameter 返回与已有记录相同的参数，用于只修改部分字段的接口
+func recordParameter(r *dnsapi.Record) *dnsapi.Parameter {
+	return &dnsapi.Parameter{
+		ID:       r.ID,
+		Domain:   r.Domain,
+		Name:     r.Name,
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      r.TTL,
+		Line:     r.Line,
+		Priority: r.Priority,
+		Weight:   r.Weight,
+		Remark:   r.Remark,
+	}
+}
@@ -0,0 +1,129 @@
+package fakeapi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/aliyun"
+	"github.com/liwanggui/dnscli-go/dnsapi/cloudflare"
+	"github.com/liwanggui/dnscli-go/dnsapi/fakeapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/tencent"
+)
+
+func TestInvalidCredential(t *testing.T) {
+	tests := []struct {
+		name   string
+		server func(t testing.TB) *fakeapi.Server
+		client func(endpoint string) (dnsapi.DNSAPI, error)
+	}{
+		{"aliyun", fakeapi.NewAliyun, func(endpoint string) (dnsapi.DNSAPI, error) {
+			return aliyun.NewClient(fakeapi.InvalidCredential, "secret", "", endpoint)
+		}},
+		{"tencent", fakeapi.NewTencent, func(endpoint string) (dnsapi.DNSAPI, error) {
+			return tencent.NewClient(fakeapi.InvalidCredential, "secret", "", endpoint)
+		}},
+		{"cloudflare", fakeapi.NewCloudflare, func(endpoint string) (dnsapi.DNSAPI, error) {
+			return cloudflare.NewClient(fakeapi.InvalidCredential, "", "", "", endpoint)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := tt.server(t)
+			client, err := tt.client(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.ListDomains(context.Background()); !errors.Is(err, dnsapi.ErrAuthFailed) {
+				t.Errorf("ListDomains 的错误 = %v, 应为 ErrAuthFailed", err)
+			}
+		})
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	tests := []struct {
+		name   string
+		server func(t testing.TB) *fakeapi.Server
+		client func(endpoint string) (dnsapi.DNSAPI, error)
+	}{
+		{"aliyun", fakeapi.NewAliyun, func(endpoint string) (dnsapi.DNSAPI, error) {
+			return aliyun.NewClient("id", "secret", "", endpoint)
+		}},
+		{"tencent", fakeapi.NewTencent, func(endpoint string) (dnsapi.DNSAPI, error) {
+			return tencent.NewClient("id", "secret", "", endpoint)
+		}},
+		{"cloudflare", fakeapi.NewCloudflare, func(endpoint string) (dnsapi.DNSAPI, error) {
+			return cloudflare.NewClient("token", "", "", "", endpoint)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			srv := tt.server(t)
+			client, err := tt.client(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.Throttle(1)
+			if _, err := client.ListDomains(ctx); !errors.Is(err, dnsapi.ErrRateLimited) {
+				t.Fatalf("ListDomains 的错误 = %v, 应为 ErrRateLimited", err)
+			}
+
+			var retries int
+			client = dnsapi.Retry(dnsapi.RetryConfig{
+				Retries:   2,
+				BaseDelay: time.Millisecond,
+				OnRetry:   func(int, time.Duration, error) { retries++ },
+			})(client)
+			srv.Throttle(2)
+			if _, err := client.ListDomains(ctx); err != nil {
+				t.Fatalf("重试后 ListDomains 失败: %v", err)
+			}
+			if retries != 2 {
+				t.Errorf("重试次数 = %d, 应为 2", retries)
+			}
+
+			srv.Throttle(3)
+			if _, err := client.ListDomains(ctx); !errors.Is(err, dnsapi.ErrRateLimited) {
+				t.Errorf("超过重试次数时 ListDomains 的错误 = %v, 应为 ErrRateLimited", err)
+			}
+		})
+	}
+}
+
+func TestCloudflareBatchRollback(t *testing.T) {
+	ctx := context.Background()
+	srv := fakeapi.NewCloudflare(t)
+	if _, err := srv.Backend.AddDomain(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	client, err := cloudflare.NewClient("token", "", "", "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []dnsapi.Change{
+		{Action: dnsapi.ChangeCreate, Param: &dnsapi.Parameter{Name: "www", Type: "A", Value: "192.0.2.1"}},
+		{Action: dnsapi.ChangeDelete, Param: &dnsapi.Parameter{ID: "999999999"}},
+	}
+	results, err := dnsapi.ApplyChanges(ctx, client, "example.com", changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, dnsapi.ErrNotFound) {
+			t.Errorf("%s 的错误 = %v, 应为 ErrNotFound", r.Change, r.Err)
+		}
+	}
+	records, err := srv.Backend.ListRecords(ctx, dnsapi.CreateParameter("example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Errorf("批量修改失败后仍然存在 %d 条记录", len(records))
+	}
+}
@@ -0,0 +1,693 @@
+package fakeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	dnspod "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/dnspod/v20210323"
+)
+
+// NewTencent 启动模拟的腾讯云 DNSPod API，客户端通过 tencent.Client.SetEndpoint 连接
+//
+// 批量接口创建的任务会立即执行完成，但与真实接口一样，DescribeBatchTask 第一次查询时任务还在排队，
+// 只返回任务类型，没有执行数量和结果。
+// https://cloud.tencent.com/document/api/1427/56193
+func NewTencent(t testing.TB) *Server {
+	return newServer(t, func(s *Server) http.Handler {
+		h := &tencentHandler{Server: s, jobs: make(map[uint64]*dnspod.DescribeBatchTaskResponseParams), polled: make(map[uint64]bool)}
+		s.rateLimited = func(w http.ResponseWriter) {
+			h.writeError(w, &apiError{http.StatusOK, "RequestLimitExceeded", "请求的次数超过了频率限制。"})
+		}
+		return h
+	})
+}
+
+type tencentHandler struct {
+	*Server
+	// jobs 批量任务的执行结果
+	jobs map[uint64]*dnspod.DescribeBatchTaskResponseParams
+	// polled 已经查询过的批量任务
+	polled map[uint64]bool
+}
+
+// tencentActions 支持的接口
+var tencentActions = map[string]func(h *tencentHandler, ctx context.Context, body []byte) (any, error){
+	"DescribeRecordList":     (*tencentHandler).describeRecordList,
+	"DescribeRecord":         (*tencentHandler).describeRecord,
+	"CreateRecord":           (*tencentHandler).createRecord,
+	"ModifyRecord":           (*tencentHandler).modifyRecord,
+	"DeleteRecord":           (*tencentHandler).deleteRecord,
+	"ModifyRecordStatus":     (*tencentHandler).modifyRecordStatus,
+	"DescribeRecordLineList": (*tencentHandler).describeRecordLineList,
+	"DescribeDomain":         (*tencentHandler).describeDomain,
+	"DescribeDomainList":     (*tencentHandler).describeDomainList,
+	"CreateDomain":           (*tencentHandler).createDomain,
+	"DeleteDomain":           (*tencentHandler).deleteDomain,
+	"CreateRecordBatch":      (*tencentHandler).createRecordBatch,
+	"DeleteRecordBatch":      (*tencentHandler).deleteRecordBatch,
+	"DescribeBatchTask":      (*tencentHandler).describeBatchTask,
+}
+
+func (h *tencentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.Contains(r.Header.Get("Authorization"), "Credential="+InvalidCredential+"/") {
+		h.writeError(w, &apiError{http.StatusOK, "AuthFailure.SecretIdNotFound", "The SecretId is not found, please ensure that your SecretId is correct."})
+		return
+	}
+	action, ok := tencentActions[r.Header.Get("X-TC-Action")]
+	if !ok {
+		h.writeError(w, &apiError{http.StatusOK, "InvalidAction", "The requested action is not found."})
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response, err := action(h, r.Context(), body)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"Response": response})
+}
+
+// writeError 按照腾讯云的格式返回错误，HTTP 状态码为 200，未指定错误码的错误按分类转换
+func (h *tencentHandler) writeError(w http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = &apiError{code: "InvalidParameter", message: errorMessage(err)}
+		switch {
+		case errors.Is(err, dnsapi.ErrNotFound):
+			e.code = "ResourceNotFound.NoDataOfRecord"
+		case errors.Is(err, dnsapi.ErrConflict):
+			e.code = "InvalidParameter.DomainRecordExist"
+		case !errors.Is(err, dnsapi.ErrInvalidInput) && !errors.Is(err, dnsapi.ErrUnsupported):
+			e.code = "InternalError"
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Response": map[string]any{
+			"Error":     map[string]string{"Code": e.code, "Message": e.message},
+			"RequestId": requestID(),
+		},
+	})
+}
+
+// decode 解析请求参数
+func decode[T any](body []byte) (*T, error) {
+	params := new(T)
+	if err := json.Unmarshal(body, params); err != nil {
+		return nil, &apiError{http.StatusOK, "InvalidParameter", err.Error()}
+	}
+	return params, nil
+}
+
+// domain 返回指定的域名，DNSPod 对不存在的域名返回 InvalidParameterValue.DomainNotExists
+func (h *tencentHandler) domain(ctx context.Context, name string) (*dnsapi.Domain, error) {
+	domain, err := h.Server.domain(ctx, name)
+	if errors.Is(err, dnsapi.ErrNotFound) {
+		return nil, &apiError{http.StatusOK, "InvalidParameterValue.DomainNotExists", "当前域名有误，请返回重新操作。"}
+	}
+	return domain, err
+}
+
+// lineNames 返回线路代码与线路名称的对应关系，DNSPod 的接口使用线路名称
+func lineNames(lines []dnsapi.Line) map[string]string {
+	names := make(map[string]string, len(lines))
+	for _, l := range lines {
+		names[l.Code] = l.Name
+	}
+	return names
+}
+
+func (h *tencentHandler) describeRecordList(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DescribeRecordListRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	domain := stringValue(params.Domain)
+	if _, err := h.domain(ctx, domain); err != nil {
+		return nil, err
+	}
+	lines, err := h.Backend.ListLines(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	param := dnsapi.CreateParameter(domain)
+	param.Type = stringValue(params.RecordType)
+	if name := stringValue(params.RecordLine); name != "" {
+		line, err := dnsapi.FindLine(lines, name)
+		if err != nil {
+			return nil, err
+		}
+		param.Line = line.Code
+	}
+	names := lineNames(lines)
+	all, err := h.Backend.ListRecords(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subdomain 精确匹配主机记录，Keyword 搜索主机记录和记录值
+	subdomain, keyword := stringValue(params.Subdomain), stringValue(params.Keyword)
+	records := make([]dnsapi.Record, 0, len(all))
+	for _, r := range all {
+		if subdomain != "" && r.Name != subdomain {
+			continue
+		}
+		if keyword != "" && !strings.Contains(r.Name, keyword) && !strings.Contains(r.Value, keyword) {
+			continue
+		}
+		records = append(records, r)
+	}
+
+	limit := uint64(100)
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit == 0 || limit > 3000 {
+		return nil, &apiError{http.StatusOK, "InvalidParameter.ParamInvalid", "Limit 的取值范围为 1-3000。"}
+	}
+	list := page(records, int(uint64Value(params.Offset)), int(limit))
+	if len(list) == 0 {
+		return nil, &apiError{http.StatusOK, "ResourceNotFound.NoDataOfRecord", "记录列表为空。"}
+	}
+	response := &dnspod.DescribeRecordListResponseParams{
+		RequestId: common.StringPtr(requestID()),
+		RecordCountInfo: &dnspod.RecordCountInfo{
+			SubdomainCount: common.Uint64Ptr(uint64(len(records))),
+			ListCount:      common.Uint64Ptr(uint64(len(list))),
+			TotalCount:     common.Uint64Ptr(uint64(len(records))),
+		},
+	}
+	for _, r := range list {
+		response.RecordList = append(response.RecordList, &dnspod.RecordListItem{
+			RecordId:      tencentID(r.ID),
+			Name:          common.StringPtr(r.Name),
+			Type:          common.StringPtr(r.Type),
+			Value:         common.StringPtr(r.Value),
+			TTL:           common.Uint64Ptr(uint64(r.TTL)),
+			MX:            common.Uint64Ptr(tencentMX(&r)),
+			Line:          common.StringPtr(names[r.Line]),
+			LineId:        common.StringPtr("0"),
+			Weight:        tencentWeight(&r),
+			Status:        common.StringPtr(r.Status),
+			Remark:        common.StringPtr(r.Remark),
+			MonitorStatus: common.StringPtr(""),
+			UpdatedOn:     common.StringPtr(r.Updated),
+			DefaultNS:     common.BoolPtr(false),
+		})
+	}
+	return response, nil
+}
+
+func (h *tencentHandler) describeRecord(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DescribeRecordRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := h.domain(ctx, stringValue(params.Domain))
+	if err != nil {
+		return nil, err
+	}
+	lines, err := h.Backend.ListLines(ctx, domain.Name)
+	if err != nil {
+		return nil, err
+	}
+	names := lineNames(lines)
+	r, err := h.Backend.GetRecord(ctx, &dnsapi.Parameter{Domain: domain.Name, ID: recordID(params.RecordId)})
+	if err != nil {
+		return nil, err
+	}
+	enabled := uint64(1)
+	if r.Status == dnsapi.StatusDisable {
+		enabled = 0
+	}
+	return &dnspod.DescribeRecordResponseParams{
+		RequestId: common.StringPtr(requestID()),
+		RecordInfo: &dnspod.RecordInfo{
+			Id:            tencentID(r.ID),
+			DomainId:      tencentID(domain.ID),
+			SubDomain:     common.StringPtr(r.Name),
+			RecordType:    common.StringPtr(r.Type),
+			RecordLine:    common.StringPtr(names[r.Line]),
+			RecordLineId:  common.StringPtr("0"),
+			Value:         common.StringPtr(r.Value),
+			TTL:           common.Uint64Ptr(uint64(r.TTL)),
+			MX:            common.Uint64Ptr(tencentMX(r)),
+			Weight:        tencentWeight(r),
+			Enabled:       common.Uint64Ptr(enabled),
+			MonitorStatus: common.StringPtr(""),
+			Remark:        common.StringPtr(r.Remark),
+			UpdatedOn:     common.StringPtr(r.Updated),
+		},
+	}, nil
+}
+
+func (h *tencentHandler) createRecord(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.CreateRecordRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if params.RecordLine == nil {
+		return nil, &apiError{http.StatusOK, "MissingParameter", "缺少参数 RecordLine。"}
+	}
+	if _, err := h.domain(ctx, stringValue(params.Domain)); err != nil {
+		return nil, err
+	}
+	param := tencentRecordParameter(stringValue(params.Domain), params.SubDomain, params.RecordType, params.RecordLine,
+		params.Value, params.TTL, params.MX, params.Weight, params.Remark)
+	id, err := h.addRecord(ctx, param, stringValue(params.Status))
+	if err != nil {
+		return nil, err
+	}
+	return &dnspod.CreateRecordResponseParams{RequestId: common.StringPtr(requestID()), RecordId: tencentID(id)}, nil
+}
+
+// addRecord 添加记录并按照 status 设置记录状态，返回新记录的 ID
+func (h *tencentHandler) addRecord(ctx context.Context, param *dnsapi.Parameter, status string) (string, error) {
+	if err := h.Backend.AddRecord(ctx, param); err != nil {
+		return "", err
+	}
+	records, err := h.Backend.ListRecords(ctx, dnsapi.CreateParameter(param.Domain))
+	if err != nil {
+		return "", err
+	}
+	id := records[len(records)-1].ID
+	if status == dnsapi.StatusDisable {
+		if err := h.Backend.SetRecordStatus(ctx, &dnsapi.Parameter{Domain: param.Domain, ID: id, Status: status}); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+func (h *tencentHandler) modifyRecord(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.ModifyRecordRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if params.RecordLine == nil {
+		return nil, &apiError{http.StatusOK, "MissingParameter", "缺少参数 RecordLine。"}
+	}
+	if _, err := h.domain(ctx, stringValue(params.Domain)); err != nil {
+		return nil, err
+	}
+	param := tencentRecordParameter(stringValue(params.Domain), params.SubDomain, params.RecordType, params.RecordLine,
+		params.Value, params.TTL, params.MX, params.Weight, params.Remark)
+	param.ID = recordID(params.RecordId)
+	if err := h.Backend.UpdateRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	if status := stringValue(params.Status); status != "" {
+		param.Status = status
+		if err := h.Backend.SetRecordStatus(ctx, param); err != nil {
+			return nil, err
+		}
+	}
+	return &dnspod.ModifyRecordResponseParams{RequestId: common.StringPtr(requestID()), RecordId: params.RecordId}, nil
+}
+
+func (h *tencentHandler) deleteRecord(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DeleteRecordRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.domain(ctx, stringValue(params.Domain)); err != nil {
+		return nil, err
+	}
+	param := &dnsapi.Parameter{Domain: stringValue(params.Domain), ID: recordID(params.RecordId)}
+	if err := h.Backend.DeleteRecord(ctx, param); err != nil {
+		return nil, err
+	}
+	return &dnspod.DeleteRecordResponseParams{RequestId: common.StringPtr(requestID())}, nil
+}
+
+func (h *tencentHandler) modifyRecordStatus(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.ModifyRecordStatusRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.domain(ctx, stringValue(params.Domain)); err != nil {
+		return nil, err
+	}
+	param := &dnsapi.Parameter{Domain: stringValue(params.Domain), ID: recordID(params.RecordId), Status: stringValue(params.Status)}
+	if err := h.Backend.SetRecordStatus(ctx, param); err != nil {
+		return nil, err
+	}
+	return &dnspod.ModifyRecordStatusResponseParams{RequestId: common.StringPtr(requestID()), RecordId: params.RecordId}, nil
+}
+
+func (h *tencentHandler) describeRecordLineList(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DescribeRecordLineListRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.domain(ctx, stringValue(params.Domain)); err != nil {
+		return nil, err
+	}
+	if stringValue(params.DomainGrade) == "" {
+		return nil, &apiError{http.StatusOK, "MissingParameter", "缺少参数 DomainGrade。"}
+	}
+	lines, err := h.Backend.ListLines(ctx, stringValue(params.Domain))
+	if err != nil {
+		return nil, err
+	}
+	response := &dnspod.DescribeRecordLineListResponseParams{RequestId: common.StringPtr(requestID())}
+	for i, l := range lines {
+		response.LineList = append(response.LineList, &dnspod.LineInfo{
+			Name:   common.StringPtr(l.Name),
+			LineId: common.StringPtr(strconv.Itoa(i)),
+		})
+	}
+	return response, nil
+}
+
+func (h *tencentHandler) describeDomain(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DescribeDomainRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	d, err := h.domain(ctx, stringValue(params.Domain))
+	if err != nil {
+		return nil, err
+	}
+	return &dnspod.DescribeDomainResponseParams{
+		RequestId: common.StringPtr(requestID()),
+		DomainInfo: &dnspod.DomainInfo{
+			DomainId:     tencentID(d.ID),
+			Domain:       common.StringPtr(d.Name),
+			Status:       common.StringPtr("ENABLE"),
+			DnsStatus:    common.StringPtr(""),
+			Grade:        common.StringPtr("DP_FREE"),
+			GradeTitle:   common.StringPtr("免费版"),
+			DnspodNsList: common.StringPtrs(d.NameServers),
+			ActualNsList: common.StringPtrs(d.NameServers),
+			RecordCount:  common.Uint64Ptr(uint64(d.RecordCount)),
+			CreatedOn:    common.StringPtr(d.Created),
+			UpdatedOn:    common.StringPtr(d.Created),
+		},
+	}, nil
+}
+
+func (h *tencentHandler) describeDomainList(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DescribeDomainListRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := h.Backend.ListDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := int64(20)
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit <= 0 || limit > 3000 {
+		return nil, &apiError{http.StatusOK, "InvalidParameter.ParamInvalid", "Limit 的取值范围为 1-3000。"}
+	}
+	var offset int64
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	list := page(domains, int(offset), int(limit))
+	if len(list) == 0 {
+		return nil, &apiError{http.StatusOK, "ResourceNotFound.NoDataOfDomain", "域名列表为空。"}
+	}
+	total := common.Uint64Ptr(uint64(len(domains)))
+	response := &dnspod.DescribeDomainListResponseParams{
+		RequestId:       common.StringPtr(requestID()),
+		DomainCountInfo: &dnspod.DomainCountInfo{DomainTotal: total, AllTotal: total, MineTotal: total},
+	}
+	for _, d := range list {
+		response.DomainList = append(response.DomainList, &dnspod.DomainListItem{
+			DomainId:     tencentID(d.ID),
+			Name:         common.StringPtr(d.Name),
+			Status:       common.StringPtr("ENABLE"),
+			Grade:        common.StringPtr("DP_FREE"),
+			GradeTitle:   common.StringPtr("免费版"),
+			EffectiveDNS: common.StringPtrs(d.NameServers),
+			RecordCount:  common.Uint64Ptr(uint64(d.RecordCount)),
+			CreatedOn:    common.StringPtr(d.Created),
+			UpdatedOn:    common.StringPtr(d.Created),
+		})
+	}
+	return response, nil
+}
+
+func (h *tencentHandler) createDomain(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.CreateDomainRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	d, err := h.Backend.AddDomain(ctx, stringValue(params.Domain))
+	if errors.Is(err, dnsapi.ErrConflict) {
+		return nil, &apiError{http.StatusOK, "InvalidParameterValue.DomainAlreadyExist", "域名已存在。"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dnspod.CreateDomainResponseParams{
+		RequestId: common.StringPtr(requestID()),
+		DomainInfo: &dnspod.DomainCreateInfo{
+			Id:          tencentID(d.ID),
+			Domain:      common.StringPtr(d.Name),
+			Punycode:    common.StringPtr(d.Name),
+			GradeNsList: common.StringPtrs(d.NameServers),
+		},
+	}, nil
+}
+
+func (h *tencentHandler) deleteDomain(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DeleteDomainRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.domain(ctx, stringValue(params.Domain)); err != nil {
+		return nil, err
+	}
+	if err := h.Backend.DeleteDomain(ctx, stringValue(params.Domain)); err != nil {
+		return nil, err
+	}
+	return &dnspod.DeleteDomainResponseParams{RequestId: common.StringPtr(requestID())}, nil
+}
+
+func (h *tencentHandler) createRecordBatch(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.CreateRecordBatchRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.DomainIdList) == 0 || len(params.RecordList) == 0 {
+		return nil, &apiError{http.StatusOK, "MissingParameter", "缺少参数 DomainIdList 或 RecordList。"}
+	}
+	var details []*dnspod.DescribeBatchTaskDetail
+	for _, domainID := range params.DomainIdList {
+		d, err := h.domainByID(ctx, stringValue(domainID))
+		if errors.Is(err, dnsapi.ErrNotFound) {
+			return nil, &apiError{http.StatusOK, "InvalidParameterValue.DomainNotExists", "当前域名有误，请返回重新操作。"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		detail := newBatchDetail(d, "add")
+		for _, r := range params.RecordList {
+			param := tencentRecordParameter(d.Name, r.SubDomain, r.RecordType, r.RecordLine, r.Value, r.TTL, r.MX, r.Weight, r.Remark)
+			info := &dnspod.BatchRecordInfo{
+				SubDomain:  r.SubDomain,
+				RecordType: r.RecordType,
+				RecordLine: r.RecordLine,
+				Value:      r.Value,
+				TTL:        r.TTL,
+				MX:         r.MX,
+				Operation:  common.StringPtr("add"),
+			}
+			status := ""
+			if r.Enabled != nil && *r.Enabled == 0 {
+				status = dnsapi.StatusDisable
+			}
+			id, err := h.addRecord(ctx, param, status)
+			setBatchResult(info, err)
+			if err == nil {
+				info.RecordId = tencentID(id)
+			}
+			detail.RecordList = append(detail.RecordList, info)
+		}
+		details = append(details, detail)
+	}
+	return &dnspod.CreateRecordBatchResponseParams{RequestId: common.StringPtr(requestID()), JobId: h.addJob("add", details)}, nil
+}
+
+func (h *tencentHandler) deleteRecordBatch(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DeleteRecordBatchRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.RecordIdList) == 0 {
+		return nil, &apiError{http.StatusOK, "MissingParameter", "缺少参数 RecordIdList。"}
+	}
+	// 按域名分组返回任务结果，不存在的记录单独作为一组
+	byDomain := make(map[string]*dnspod.DescribeBatchTaskDetail)
+	var details []*dnspod.DescribeBatchTaskDetail
+	for _, id := range params.RecordIdList {
+		info := &dnspod.BatchRecordInfo{RecordId: id, Operation: common.StringPtr("delete")}
+		var domain dnsapi.Domain
+		r, err := h.record(ctx, recordID(id))
+		if err == nil {
+			domain.Name = r.Domain
+			info.SubDomain, info.RecordType, info.Value = common.StringPtr(r.Name), common.StringPtr(r.Type), common.StringPtr(r.Value)
+			err = h.Backend.DeleteRecord(ctx, recordParameter(r))
+		}
+		setBatchResult(info, err)
+
+		detail, ok := byDomain[domain.Name]
+		if !ok {
+			if domain.Name != "" {
+				d, err := h.Server.domain(ctx, domain.Name)
+				if err != nil {
+					return nil, err
+				}
+				domain = *d
+			}
+			detail = newBatchDetail(&domain, "delete")
+			byDomain[domain.Name] = detail
+			details = append(details, detail)
+		}
+		detail.RecordList = append(detail.RecordList, info)
+	}
+	return &dnspod.DeleteRecordBatchResponseParams{RequestId: common.StringPtr(requestID()), JobId: h.addJob("delete", details)}, nil
+}
+
+func (h *tencentHandler) describeBatchTask(ctx context.Context, body []byte) (any, error) {
+	params, err := decode[dnspod.DescribeBatchTaskRequestParams](body)
+	if err != nil {
+		return nil, err
+	}
+	job, ok := h.jobs[uint64Value(params.JobId)]
+	if !ok {
+		return nil, &apiError{http.StatusOK, "InvalidParameterValue.JobNotExists", "任务不存在。"}
+	}
+	if !h.polled[uint64Value(params.JobId)] {
+		h.polled[uint64Value(params.JobId)] = true
+		return &dnspod.DescribeBatchTaskResponseParams{JobType: job.JobType, CreatedAt: job.CreatedAt, RequestId: common.StringPtr(requestID())}, nil
+	}
+	response := *job
+	response.RequestId = common.StringPtr(requestID())
+	return &response, nil
+}
+
+// addJob 保存已经执行完成的批量任务，返回任务 ID
+func (h *tencentHandler) addJob(jobType string, details []*dnspod.DescribeBatchTaskDetail) *uint64 {
+	var success, fail uint64
+	for _, d := range details {
+		for _, r := range d.RecordList {
+			if stringValue(r.Status) == "success" {
+				success++
+			} else {
+				fail++
+			}
+		}
+	}
+	id := uint64(len(h.jobs) + 1)
+	h.jobs[id] = &dnspod.DescribeBatchTaskResponseParams{
+		DetailList:   details,
+		TotalCount:   common.Uint64Ptr(success + fail),
+		SuccessCount: common.Uint64Ptr(success),
+		FailCount:    common.Uint64Ptr(fail),
+		JobType:      common.StringPtr(jobType),
+		CreatedAt:    common.StringPtr(time.Now().Format(time.DateTime)),
+	}
+	return common.Uint64Ptr(id)
+}
+
+func newBatchDetail(d *dnsapi.Domain, operation string) *dnspod.DescribeBatchTaskDetail {
+	return &dnspod.DescribeBatchTaskDetail{
+		Id:          tencentID(d.ID),
+		DomainId:    tencentID(d.ID),
+		Domain:      common.StringPtr(d.Name),
+		DomainGrade: common.StringPtr("DP_FREE"),
+		Status:      common.StringPtr("success"),
+		Operation:   common.StringPtr(operation),
+	}
+}
+
+// setBatchResult 设置批量任务中一条记录的执行结果
+func setBatchResult(info *dnspod.BatchRecordInfo, err error) {
+	if err != nil {
+		info.Status = common.StringPtr("failed")
+		info.ErrMsg = common.StringPtr(errorMessage(err))
+		return
+	}
+	info.Status = common.StringPtr("success")
+}
+
+// tencentRecordParameter 根据添加和修改记录接口的参数创建 dnsapi.Parameter
+func tencentRecordParameter(domain string, name, rType, line, value *string, ttl, mx, weight *uint64, remark *string) *dnsapi.Parameter {
+	param := &dnsapi.Parameter{
+		Domain: domain,
+		Name:   stringValue(name),
+		Type:   stringValue(rType),
+		Line:   stringValue(line),
+		Value:  stringValue(value),
+		TTL:    int(uint64Value(ttl)),
+		Weight: int(uint64Value(weight)),
+		Remark: stringValue(remark),
+		// 显式传入 0 时将权重设置为 0
+		ZeroWeight: weight != nil && *weight == 0,
+	}
+	if param.Name == "" {
+		param.Name = "@"
+	}
+	if param.Type == "MX" {
+		param.Priority = int(uint64Value(mx))
+	}
+	return param
+}
+
+// tencentMX DNSPod 只返回 MX 记录的优先级，其他记录为 0
+func tencentMX(r *dnsapi.Record) uint64 {
+	if r.Type == "MX" {
+		return uint64(r.Priority)
+	}
+	return 0
+}
+
+// tencentWeight 未设置权重的记录返回 null
+func tencentWeight(r *dnsapi.Record) *uint64 {
+	if r.Weight == 0 {
+		return nil
+	}
+	return common.Uint64Ptr(uint64(r.Weight))
+}
+
+// tencentID 将 ID 转换为 DNSPod 使用的整数 ID
+func tencentID(id string) *uint64 {
+	n, _ := strconv.ParseUint(id, 10, 64)
+	return &n
+}
+
+func recordID(id *uint64) string {
+	return strconv.FormatUint(uint64Value(id), 10)
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func uint64Value(v *uint64) uint64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,434 @@
+// Package local 实现保存在本地 JSON 文件中的 DNS 服务商，不需要云服务商的认证信息，
+// 用于离线演示、编写脚本和测试。
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+func init() {
+	dnsapi.Register(&dnsapi.Provider{
+		Name:        "local",
+		Description: "本地 JSON 文件，用于离线演示和测试",
+		Credentials: []dnsapi.CredentialField{
+			{Key: "data_file", Description: "保存解析数据的 JSON 文件路径，默认为 $HOME/.dnscli/local.json"},
+		},
+		New: func(cfg dnsapi.Config) (dnsapi.DNSAPI, error) {
+			return NewClient(cfg.Get("data_file"))
+		},
+	})
+}
+
+// minTTL 最小 TTL，与阿里云和 DNSPod 的免费版一致
+const minTTL = 600
+
+// nameServers 添加域名时分配的 DNS 服务器
+var nameServers = []string{"ns1.dnscli.local", "ns2.dnscli.local"}
+
+// lines 支持的解析线路
+var lines = []dnsapi.Line{
+	{Code: "default", Name: "默认"},
+	{Code: "telecom", Name: "电信", Parent: "default"},
+	{Code: "unicom", Name: "联通", Parent: "default"},
+	{Code: "mobile", Name: "移动", Parent: "default"},
+	{Code: "oversea", Name: "境外", Parent: "default"},
+}
+
+// defaultLine 未指定线路时使用的线路
+const defaultLine = "default"
+
+// store 数据文件的内容
+type store struct {
+	// NextID 下一个域名或记录的 ID
+	NextID  int64   `json:"next_id"`
+	Domains []*zone `json:"domains"`
+}
+
+// zone 一个域名及其解析记录
+type zone struct {
+	dnsapi.Domain
+	Records []dnsapi.Record `json:"records"`
+}
+
+// Client 使用本地 JSON 文件保存数据的 DNS 服务商
+type Client struct {
+	path string
+	// mu 保证同一进程内对数据文件的读写是串行的
+	mu sync.Mutex
+}
+
+// NewClient 创建使用 path 保存数据的客户端，path 为空时使用 $HOME/.dnscli/local.json
+func NewClient(path string) (*Client, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("获取用户主目录失败: %w", err)
+		}
+		path = filepath.Join(home, ".dnscli", "local.json")
+	}
+	return &Client{path: path}, nil
+}
+
+// load 读取数据文件，文件不存在时返回空数据
+func (c *Client) load() (*store, error) {
+	s := &store{NextID: 1}
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取数据文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("解析数据文件 %s 失败: %w", c.path, err)
+	}
+	return s, nil
+}
+
+// save 写入数据文件，先写临时文件再重命名，避免写入中断时损坏数据
+func (c *Client) save(s *store) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("写入数据文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("写入数据文件失败: %w", err)
+	}
+	return nil
+}
+
+// view 在只读的数据上执行 fn
+func (c *Client) view(ctx context.Context, fn func(s *store) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, err := c.load()
+	if err != nil {
+		return err
+	}
+	return fn(s)
+}
+
+// update 在数据上执行 fn，fn 成功时保存修改
+func (c *Client) update(ctx context.Context, fn func(s *store) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, err := c.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	return c.save(s)
+}
+
+// nextID 分配一个新的 ID
+func (s *store) nextID() string {
+	id := s.NextID
+	s.NextID++
+	return strconv.FormatInt(id, 10)
+}
+
+// zone 返回指定的域名
+func (s *store) zone(domain string) (*zone, error) {
+	for _, z := range s.Domains {
+		if z.Name == domain {
+			return z, nil
+		}
+	}
+	return nil, dnsapi.Errorf(dnsapi.ErrNotFound, "域名不存在: %s", domain)
+}
+
+// record 返回指定 ID 的解析记录在 Records 中的下标
+func (z *zone) record(id string) (int, error) {
+	if id == "" {
+		return 0, dnsapi.Errorf(dnsapi.ErrInvalidInput, "记录 ID 不能为空")
+	}
+	for i, r := range z.Records {
+		if r.ID == id {
+			return i, nil
+		}
+	}
+	return 0, dnsapi.Errorf(dnsapi.ErrNotFound, "解析记录不存在: %s", id)
+}
+
+// ListRecords 获取指定域名的所有解析记录，主机记录和记录值按包含的关键字过滤
+func (c *Client) ListRecords(ctx context.Context, param *dnsapi.Parameter) ([]dnsapi.Record, error) {
+	records := make([]dnsapi.Record, 0)
+	err := c.view(ctx, func(s *store) error {
+		z, err := s.zone(param.Domain)
+		if err != nil {
+			return err
+		}
+		for _, r := range z.Records {
+			if !strings.Contains(r.Name, param.Name) || !strings.Contains(r.Value, param.Value) {
+				continue
+			}
+			if (param.Type != "" && r.Type != param.Type) || (param.Line != "" && r.Line != param.Line) {
+				continue
+			}
+			records = append(records, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetRecord 获取特定记录的详情
+func (c *Client) GetRecord(ctx context.Context, param *dnsapi.Parameter) (*dnsapi.Record, error) {
+	var record dnsapi.Record
+	err := c.view(ctx, func(s *store) error {
+		z, err := s.zone(param.Domain)
+		if err != nil {
+			return err
+		}
+		i, err := z.record(param.ID)
+		if err != nil {
+			return err
+		}
+		record = z.Records[i]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// AddRecord 添加新的解析记录
+func (c *Client) AddRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	if err := validParameter(param); err != nil {
+		return err
+	}
+	var id string
+	err := c.update(ctx, func(s *store) error {
+		z, err := s.zone(param.Domain)
+		if err != nil {
+			return err
+		}
+		record := newRecord(param, "")
+		if err := checkConflict(z.Records, &record); err != nil {
+			return err
+		}
+		record.ID = s.nextID()
+		z.Records = append(z.Records, record)
+		id = record.ID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	param.ID = id
+	return nil
+}
+
+// UpdateRecord 更新现有解析记录，未指定的线路、备注和权重保持不变
+func (c *Client) UpdateRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	if err := validParameter(param); err != nil {
+		return err
+	}
+	return c.update(ctx, func(s *store) error {
+		z, err := s.zone(param.Domain)
+		if err != nil {
+			return err
+		}
+		i, err := z.record(param.ID)
+		if err != nil {
+			return err
+		}
+		old := z.Records[i]
+		record := newRecord(param, old.Status)
+		if param.Line == "" {
+			record.Line = old.Line
+		}
+		if param.Remark == "" {
+			record.Remark = old.Remark
+		}
+		if param.Weight == 0 && !param.ZeroWeight {
+			record.Weight = old.Weight
+		}
+		if err := checkConflict(slices.Delete(slices.Clone(z.Records), i, i+1), &record); err != nil {
+			return err
+		}
+		z.Records[i] = record
+		return nil
+	})
+}
+
+// DeleteRecord 删除解析记录
+func (c *Client) DeleteRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	return c.update(ctx, func(s *store) error {
+		z, err := s.zone(param.Domain)
+		if err != nil {
+			return err
+		}
+		i, err := z.record(param.ID)
+		if err != nil {
+			return err
+		}
+		z.Records = slices.Delete(z.Records, i, i+1)
+		return nil
+	})
+}
+
+// SetRecordStatus 启用或暂停解析记录
+func (c *Client) SetRecordStatus(ctx context.Context, param *dnsapi.Parameter) error {
+	if err := dnsapi.ValidStatus(param.Status); err != nil {
+		return err
+	}
+	return c.update(ctx, func(s *store) error {
+		z, err := s.zone(param.Domain)
+		if err != nil {
+			return err
+		}
+		i, err := z.record(param.ID)
+		if err != nil {
+			return err
+		}
+		z.Records[i].Status = param.Status
+		z.Records[i].Updated = time.Now().Format(time.DateTime)
+		return nil
+	})
+}
+
+// ListLines 列出支持的解析线路，所有域名相同
+func (c *Client) ListLines(ctx context.Context, domain string) ([]dnsapi.Line, error) {
+	err := c.view(ctx, func(s *store) error {
+		_, err := s.zone(domain)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(lines), nil
+}
+
+// ListDomains 列出所有域名
+func (c *Client) ListDomains(ctx context.Context) ([]dnsapi.Domain, error) {
+	domains := make([]dnsapi.Domain, 0)
+	err := c.view(ctx, func(s *store) error {
+		for _, z := range s.Domains {
+			domain := z.Domain
+			domain.RecordCount = len(z.Records)
+			domains = append(domains, domain)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// AddDomain 添加域名
+func (c *Client) AddDomain(ctx context.Context, name string) (*dnsapi.Domain, error) {
+	if err := validDomain(name); err != nil {
+		return nil, err
+	}
+	var domain dnsapi.Domain
+	err := c.update(ctx, func(s *store) error {
+		if _, err := s.zone(name); err == nil {
+			return dnsapi.Errorf(dnsapi.ErrConflict, "域名已存在: %s", name)
+		}
+		domain = dnsapi.Domain{
+			ID:          s.nextID(),
+			Name:        name,
+			Status:      "normal",
+			Plan:        "local",
+			NameServers: nameServers,
+			Created:     time.Now().Format(time.DateTime),
+		}
+		s.Domains = append(s.Domains, &zone{Domain: domain, Records: []dnsapi.Record{}})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// DeleteDomain 删除域名及其所有解析记录
+func (c *Client) DeleteDomain(ctx context.Context, domain string) error {
+	return c.update(ctx, func(s *store) error {
+		for i, z := range s.Domains {
+			if z.Name == domain {
+				s.Domains = slices.Delete(s.Domains, i, i+1)
+				return nil
+			}
+		}
+		return dnsapi.Errorf(dnsapi.ErrNotFound, "域名不存在: %s", domain)
+	})
+}
+
+// Capabilities 返回本地服务商支持的功能
+func (c *Client) Capabilities() dnsapi.Capabilities {
+	return dnsapi.Capabilities{
+		Lines:       true,
+		Priority:    true,
+		Weight:      true,
+		MinWeight:   0,
+		MaxWeight:   100,
+		Remark:      true,
+		Status:      true,
+		Updated:     true,
+		MinTTL:      minTTL,
+		RecordTypes: dnsapi.RecordTypes,
+	}
+}
+
+// newRecord 根据已校验的参数创建解析记录，status 为空时记录为启用状态
+func newRecord(param *dnsapi.Parameter, status string) dnsapi.Record {
+	if status == "" {
+		status = dnsapi.StatusEnable
+	}
+	record := dnsapi.Record{
+		ID:       param.ID,
+		Domain:   param.Domain,
+		Name:     param.Name,
+		Type:     param.Type,
+		Value:    param.Value,
+		TTL:      param.TTL,
+		Line:     param.Line,
+		Priority: param.Priority,
+		Weight:   param.Weight,
+		Status:   status,
+		Remark:   param.Remark,
+		Updated:  time.Now().Format(time.DateTime),
+	}
+	if record.TTL == 0 {
+		record.TTL = minTTL
+	}
+	if record.Line == "" {
+		record.Line = defaultLine
+	}
+	record.SetData()
+	return record
+}
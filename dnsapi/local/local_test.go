@@ -0,0 +1,26 @@
+package local
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/dnsapitest"
+)
+
+func TestConformance(t *testing.T) {
+	dnsapitest.Run(t, dnsapitest.Config{
+		Domain: "example.com",
+		New: func(t *testing.T) dnsapi.DNSAPI {
+			client, err := NewClient(filepath.Join(t.TempDir(), "local.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.AddDomain(context.Background(), "example.com"); err != nil {
+				t.Fatal(err)
+			}
+			return client
+		},
+	})
+}
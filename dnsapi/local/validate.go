@@ -0,0 +1,114 @@
+package local
+
+import (
+	"net"
+	"regexp"
+	"slices"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+)
+
+var (
+	// hostnameRegex 域名，允许以 . 结尾
+	hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?\.)*[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?\.?$`)
+	// nameRegex 主机记录，允许 @ 和泛解析 *
+	nameRegex = regexp.MustCompile(`^(@|(\*\.)?([a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?\.)*[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?|\*)$`)
+)
+
+// caaTags CAA 记录支持的标签
+var caaTags = []string{"issue", "issuewild", "iodef"}
+
+// validDomain 校验域名格式
+func validDomain(domain string) error {
+	if domain == "" || len(domain) > 253 || !hostnameRegex.MatchString(domain) {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的域名：%s", domain)
+	}
+	return nil
+}
+
+// validParameter 按照云服务商的规则校验添加和更新记录的参数，
+// SRV、CAA、MX 记录的值会被转换为统一的格式
+func validParameter(param *dnsapi.Parameter) error {
+	if param.Domain == "" {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "域名不能为空")
+	}
+	if !nameRegex.MatchString(param.Name) {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的主机记录：%s", param.Name)
+	}
+	if err := dnsapi.ValidRecordType(param.Type); err != nil {
+		return err
+	}
+	if param.Value == "" && param.SRV == nil && param.CAA == nil && param.MX == nil {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "记录值不能为空")
+	}
+	if param.TTL != 0 && (param.TTL < minTTL || param.TTL > 86400) {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "TTL 的取值范围为 %d-86400", minTTL)
+	}
+	if param.Line != "" {
+		// 与阿里云一致，记录中保存线路代码
+		line, err := dnsapi.FindLine(lines, param.Line)
+		if err != nil {
+			return err
+		}
+		param.Line = line.Code
+	}
+	if param.Weight < 0 || param.Weight > 100 {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "记录权重的取值范围为 1-100")
+	}
+	if err := param.ParseData(); err != nil {
+		return err
+	}
+	return validValue(param)
+}
+
+// validValue 按记录类型校验记录值
+func validValue(param *dnsapi.Parameter) error {
+	switch param.Type {
+	case "A":
+		if ip := net.ParseIP(param.Value); ip == nil || ip.To4() == nil {
+			return invalidValue(param)
+		}
+	case "AAAA":
+		if ip := net.ParseIP(param.Value); ip == nil || ip.To4() != nil {
+			return invalidValue(param)
+		}
+	case "CNAME", "NS", "MX":
+		if validDomain(param.Value) != nil {
+			return invalidValue(param)
+		}
+	case "SRV":
+		if param.SRV.Target != "." && validDomain(param.SRV.Target) != nil {
+			return invalidValue(param)
+		}
+	case "CAA":
+		if !slices.Contains(caaTags, param.CAA.Tag) || param.CAA.Value == "" {
+			return invalidValue(param)
+		}
+	case "TXT":
+		if len(param.Value) > 512 {
+			return dnsapi.Errorf(dnsapi.ErrInvalidInput, "TXT 记录值不能超过 512 个字符")
+		}
+	}
+	return nil
+}
+
+func invalidValue(param *dnsapi.Parameter) error {
+	return dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的 %s 记录值：%s", param.Type, param.Value)
+}
+
+// checkConflict 检查新记录是否与已有记录冲突：
+// 相同主机记录、类型、线路和记录值的记录已存在，或者 CNAME 记录与同名同线路的其他记录共存
+func checkConflict(records []dnsapi.Record, record *dnsapi.Record) error {
+	for _, r := range records {
+		if r.Name != record.Name || r.Line != record.Line {
+			continue
+		}
+		if r.Type == record.Type && r.Value == record.Value {
+			return dnsapi.Errorf(dnsapi.ErrConflict, "解析记录已存在：%s %s %s", r.Name, r.Type, r.Value)
+		}
+		if (r.Type == "CNAME") != (record.Type == "CNAME") {
+			return dnsapi.Errorf(dnsapi.ErrConflict, "CNAME 记录不能与同名的其他记录共存：%s", r.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package dnsapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Middleware 包装 DNSAPI，在不修改服务商实现的情况下添加日志、重试、只读等通用逻辑
+type Middleware func(DNSAPI) DNSAPI
+
+// Chain 依次使用 middlewares 包装 api，第一个中间件在最外层，最先收到调用，nil 会被忽略
+func Chain(api DNSAPI, middlewares ...Middleware) DNSAPI {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			api = middlewares[i](api)
+		}
+	}
+	return api
+}
+
+// Call 描述一次 DNSAPI 方法调用
+type Call struct {
+	// Method 方法名，如 ListRecords、ApplyChanges
+	Method string
+	// Domain 操作的域名，ListDomains 为空
+	Domain string
+	// Param 解析记录相关方法的参数，其他方法为 nil
+	Param *Parameter
+	// Changes ApplyChanges 的修改列表
+	Changes []Change
+}
+
+// Mutating 判断调用是否会修改服务商的数据
+func (c *Call) Mutating() bool {
+	switch c.Method {
+	case "AddRecord", "UpdateRecord", "DeleteRecord", "SetRecordStatus", "AddDomain", "DeleteDomain", "ApplyChanges":
+		return true
+	}
+	return false
+}
+
+// Idempotent 判断调用重复执行的结果是否与执行一次相同
+//
+// 删除按幂等处理，重复删除只会返回 ErrNotFound。
+func (c *Call) Idempotent() bool {
+	switch c.Method {
+	case "AddRecord", "AddDomain", "ApplyChanges":
+		return false
+	}
+	return true
+}
+
+// String 返回调用的简要说明，如 `AddRecord example.com www A 1.1.1.1`
+func (c *Call) String() string {
+	parts := []string{c.Method}
+	if c.Domain != "" {
+		parts = append(parts, c.Domain)
+	}
+	if c.Changes != nil {
+		parts = append(parts, fmt.Sprintf("(%d 项修改)", len(c.Changes)))
+	}
+	if p := c.Param; p != nil && c.Method != "ListRecords" {
+		for _, s := range []string{p.ID, p.Name, p.Type, p.Value, p.Status} {
+			if s != "" {
+				parts = append(parts, s)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Interceptor 拦截 DNSAPI 调用，通过 invoke 执行被包装的方法，可以多次调用，不调用时跳过该方法
+type Interceptor func(ctx context.Context, call *Call, invoke func(ctx context.Context) error) error
+
+// Intercept 返回使用 fn 拦截除 Capabilities 外所有方法的中间件
+//
+// fn 没有调用 invoke 且返回 nil 时，AddDomain 返回只包含域名的 Domain，
+// ApplyChanges 返回全部成功的结果，其他方法返回零值。
+// 被包装的客户端实现了 Batcher 时，返回的客户端同样实现 Batcher。
+func Intercept(fn Interceptor) Middleware {
+	return func(api DNSAPI) DNSAPI {
+		c := &interceptor{api: api, fn: fn}
+		if b, ok := api.(Batcher); ok {
+			return &interceptBatcher{interceptor: c, batcher: b}
+		}
+		return c
+	}
+}
+
+// Logging 返回记录每次调用及其耗时和结果的中间件
+func Logging(logf func(format string, a ...any)) Middleware {
+	return Intercept(func(ctx context.Context, call *Call, invoke func(ctx context.Context) error) error {
+		start := time.Now()
+		err := invoke(ctx)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			logf("%s: %s, 失败: %v", call, elapsed, err)
+		} else {
+			logf("%s: %s", call, elapsed)
+		}
+		return err
+	})
+}
+
+// Timing 返回统计调用耗时的中间件，每次调用结束后调用 observe
+func Timing(observe func(call *Call, elapsed time.Duration, err error)) Middleware {
+	return Intercept(func(ctx context.Context, call *Call, invoke func(ctx context.Context) error) error {
+		start := time.Now()
+		err := invoke(ctx)
+		observe(call, time.Since(start), err)
+		return err
+	})
+}
+
+// ReadOnly 返回拒绝所有修改操作的中间件，被拒绝的调用返回 ErrDenied
+func ReadOnly() Middleware {
+	return Intercept(func(ctx context.Context, call *Call, invoke func(ctx context.Context) error) error {
+		if call.Mutating() {
+			return Errorf(ErrDenied, "只读模式下不允许修改: %s", call)
+		}
+		return invoke(ctx)
+	})
+}
+
+type interceptor struct {
+	api DNSAPI
+	fn  Interceptor
+}
+
+func (c *interceptor) ListRecords(ctx context.Context, param *Parameter) (records []Record, err error) {
+	err = c.fn(ctx, &Call{Method: "ListRecords", Domain: param.Domain, Param: param}, func(ctx context.Context) (err error) {
+		records, err = c.api.ListRecords(ctx, param)
+		return err
+	})
+	return records, err
+}
+
+func (c *interceptor) GetRecord(ctx context.Context, param *Parameter) (record *Record, err error) {
+	err = c.fn(ctx, &Call{Method: "GetRecord", Domain: param.Domain, Param: param}, func(ctx context.Context) (err error) {
+		record, err = c.api.GetRecord(ctx, param)
+		return err
+	})
+	return record, err
+}
+
+func (c *interceptor) AddRecord(ctx context.Context, param *Parameter) error {
+	return c.fn(ctx, &Call{Method: "AddRecord", Domain: param.Domain, Param: param}, func(ctx context.Context) error {
+		return c.api.AddRecord(ctx, param)
+	})
+}
+
+func (c *interceptor) UpdateRecord(ctx context.Context, param *Parameter) error {
+	return c.fn(ctx, &Call{Method: "UpdateRecord", Domain: param.Domain, Param: param}, func(ctx context.Context) error {
+		return c.api.UpdateRecord(ctx, param)
+	})
+}
+
+func (c *interceptor) DeleteRecord(ctx context.Context, param *Parameter) error {
+	return c.fn(ctx, &Call{Method: "DeleteRecord", Domain: param.Domain, Param: param}, func(ctx context.Context) error {
+		return c.api.DeleteRecord(ctx, param)
+	})
+}
+
+func (c *interceptor) SetRecordStatus(ctx context.Context, param *Parameter) error {
+	return c.fn(ctx, &Call{Method: "SetRecordStatus", Domain: param.Domain, Param: param}, func(ctx context.Context) error {
+		return c.api.SetRecordStatus(ctx, param)
+	})
+}
+
+func (c *interceptor) ListLines(ctx context.Context, domain string) (lines []Line, err error) {
+	err = c.fn(ctx, &Call{Method: "ListLines", Domain: domain}, func(ctx context.Context) (err error) {
+		lines, err = c.api.ListLines(ctx, domain)
+		return err
+	})
+	return lines, err
+}
+
+func (c *interceptor) ListDomains(ctx context.Context) (domains []Domain, err error) {
+	err = c.fn(ctx, &Call{Method: "ListDomains"}, func(ctx context.Context) (err error) {
+		domains, err = c.api.ListDomains(ctx)
+		return err
+	})
+	return domains, err
+}
+
+func (c *interceptor) AddDomain(ctx context.Context, domain string) (d *Domain, err error) {
+	err = c.fn(ctx, &Call{Method: "AddDomain", Domain: domain}, func(ctx context.Context) (err error) {
+		d, err = c.api.AddDomain(ctx, domain)
+		return err
+	})
+	if err == nil && d == nil {
+		d = &Domain{Name: domain}
+	}
+	return d, err
+}
+
+func (c *interceptor) DeleteDomain(ctx context.Context, domain string) error {
+	return c.fn(ctx, &Call{Method: "DeleteDomain", Domain: domain}, func(ctx context.Context) error {
+		return c.api.DeleteDomain(ctx, domain)
+	})
+}
+
+func (c *interceptor) Capabilities() Capabilities {
+	return c.api.Capabilities()
+}
+
+// interceptBatcher 被包装的客户端实现了 Batcher 时使用
+type interceptBatcher struct {
+	*interceptor
+	batcher Batcher
+}
+
+func (c *interceptBatcher) ApplyChanges(ctx context.Context, domain string, changes []Change) (results []ChangeResult, err error) {
+	err = c.fn(ctx, &Call{Method: "ApplyChanges", Domain: domain, Changes: changes}, func(ctx context.Context) (err error) {
+		results, err = c.batcher.ApplyChanges(ctx, domain, changes)
+		return err
+	})
+	if err == nil && results == nil {
+		results = ChangeResults(changes, nil)
+	}
+	return results, err
+}
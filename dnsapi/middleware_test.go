@@ -0,0 +1,43 @@
+package dnsapi
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return Intercept(func(ctx context.Context, call *Call, invoke func(ctx context.Context) error) error {
+			order = append(order, name+" "+call.Method)
+			return invoke(ctx)
+		})
+	}
+	f := &flaky{}
+	api := Chain(f, trace("outer"), nil, trace("inner"))
+	if err := listDomains(api); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"outer ListDomains", "inner ListDomains"}; !slices.Equal(order, want) {
+		t.Errorf("调用顺序 = %v, 应为 %v", order, want)
+	}
+	if f.calls != 1 {
+		t.Errorf("调用次数 = %d, 应为 1", f.calls)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	f := &flaky{}
+	api := ReadOnly()(f)
+	if err := listDomains(api); err != nil {
+		t.Errorf("只读模式下查询失败: %v", err)
+	}
+	if err := addRecord(api); !errors.Is(err, ErrDenied) {
+		t.Errorf("只读模式下添加记录的错误 = %v, 应为 ErrDenied", err)
+	}
+	if f.calls != 1 {
+		t.Errorf("调用次数 = %d, 应为 1", f.calls)
+	}
+}
@@ -0,0 +1,170 @@
+package dnsapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SRV SRV 记录的结构化数据，记录值格式为：优先级 权重 端口 目标地址
+type SRV struct {
+	Priority int    `json:"priority"`
+	Weight   int    `json:"weight"`
+	Port     int    `json:"port"`
+	Target   string `json:"target"`
+}
+
+// ParseSRV 解析 SRV 记录值，如 `10 5 5060 sip.example.com`
+func ParseSRV(value string) (*SRV, error) {
+	fields, rest, ok := cutFields(value, 3)
+	if !ok || rest == "" || strings.ContainsAny(rest, " \t") {
+		return nil, Errorf(ErrInvalidInput, "无效的 SRV 记录值：%s，格式为：优先级 权重 端口 目标地址", value)
+	}
+	n, err := atoi(fields...)
+	if err != nil {
+		return nil, Errorf(ErrInvalidInput, "无效的 SRV 记录值：%s，优先级、权重和端口必须为数字", value)
+	}
+	return &SRV{Priority: n[0], Weight: n[1], Port: n[2], Target: rest}, nil
+}
+
+func (s *SRV) String() string {
+	return fmt.Sprintf("%d %d %d %s", s.Priority, s.Weight, s.Port, s.Target)
+}
+
+// CAA CAA 记录的结构化数据，记录值格式为：标志 标签 "值"
+type CAA struct {
+	Flags int    `json:"flags"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// ParseCAA 解析 CAA 记录值，如 `0 issue "letsencrypt.org"`，值两边的引号可以省略
+func ParseCAA(value string) (*CAA, error) {
+	fields, rest, ok := cutFields(value, 2)
+	if !ok || rest == "" {
+		return nil, Errorf(ErrInvalidInput, "无效的 CAA 记录值：%s，格式为：标志 标签 \"值\"", value)
+	}
+	n, err := atoi(fields[0])
+	if err != nil {
+		return nil, Errorf(ErrInvalidInput, "无效的 CAA 记录值：%s，标志必须为数字", value)
+	}
+	if len(rest) >= 2 && strings.HasPrefix(rest, `"`) && strings.HasSuffix(rest, `"`) {
+		rest = rest[1 : len(rest)-1]
+	}
+	return &CAA{Flags: n[0], Tag: fields[1], Value: rest}, nil
+}
+
+func (c *CAA) String() string {
+	return fmt.Sprintf("%d %s %q", c.Flags, c.Tag, c.Value)
+}
+
+// MX MX 记录的结构化数据，记录值格式为：优先级 邮件服务器
+type MX struct {
+	Preference int    `json:"preference"`
+	Exchange   string `json:"exchange"`
+}
+
+// ParseMX 解析 MX 记录值，如 `10 mx.example.com`
+func ParseMX(value string) (*MX, error) {
+	fields, rest, ok := cutFields(value, 1)
+	if !ok || rest == "" || strings.ContainsAny(rest, " \t") {
+		return nil, Errorf(ErrInvalidInput, "无效的 MX 记录值：%s，格式为：优先级 邮件服务器", value)
+	}
+	n, err := atoi(fields[0])
+	if err != nil {
+		return nil, Errorf(ErrInvalidInput, "无效的 MX 记录值：%s，优先级必须为数字", value)
+	}
+	return &MX{Preference: n[0], Exchange: rest}, nil
+}
+
+func (m *MX) String() string {
+	return fmt.Sprintf("%d %s", m.Preference, m.Exchange)
+}
+
+// ParseData 解析 SRV、CAA 和 MX 记录的结构化数据
+//
+// 结构化数据已设置时以它为准，否则从 Value 中解析：SRV 记录值可以省略优先级，此时使用 Priority；
+// MX 记录值可以只有邮件服务器，此时使用 Priority。解析后 Value 和 Priority 会被统一为
+// 与 Record 相同的格式：SRV、CAA 为完整的记录值，MX 为邮件服务器，优先级保存在 Priority 中。
+func (p *Parameter) ParseData() error {
+	var err error
+	switch p.Type {
+	case "SRV":
+		if p.SRV == nil {
+			value := p.Value
+			if _, rest, ok := cutFields(value, 2); ok && !strings.ContainsAny(rest, " \t") {
+				value = fmt.Sprintf("%d %s", p.Priority, value)
+			}
+			if p.SRV, err = ParseSRV(value); err != nil {
+				return err
+			}
+		}
+		p.Value = p.SRV.String()
+		p.Priority = p.SRV.Priority
+	case "CAA":
+		if p.CAA == nil {
+			if p.CAA, err = ParseCAA(p.Value); err != nil {
+				return err
+			}
+		}
+		p.Value = p.CAA.String()
+	case "MX":
+		if p.MX == nil {
+			if strings.ContainsAny(strings.TrimSpace(p.Value), " \t") {
+				if p.MX, err = ParseMX(p.Value); err != nil {
+					return err
+				}
+			} else {
+				p.MX = &MX{Preference: p.Priority, Exchange: strings.TrimSpace(p.Value)}
+			}
+		}
+		p.Value = p.MX.Exchange
+		p.Priority = p.MX.Preference
+	}
+	return nil
+}
+
+// SetData 根据 Value 和 Priority 填充 SRV、CAA 和 MX 记录的结构化数据，无法解析时忽略
+func (r *Record) SetData() {
+	switch r.Type {
+	case "SRV":
+		if srv, err := ParseSRV(r.Value); err == nil {
+			r.SRV = srv
+			r.Priority = srv.Priority
+		}
+	case "CAA":
+		if caa, err := ParseCAA(r.Value); err == nil {
+			r.CAA = caa
+			r.Value = caa.String()
+		}
+	case "MX":
+		r.MX = &MX{Preference: r.Priority, Exchange: r.Value}
+	}
+}
+
+// cutFields 拆分出 s 开头以空白字符分隔的 n 个字段，剩余部分去掉两端空白后返回
+func cutFields(s string, n int) ([]string, string, bool) {
+	rest := strings.TrimSpace(s)
+	fields := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			return nil, "", false
+		}
+		fields = append(fields, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	return fields, rest, true
+}
+
+func atoi(fields ...string) ([]int, error) {
+	numbers := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.ParseUint(f, 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, int(n))
+	}
+	return numbers, nil
+}
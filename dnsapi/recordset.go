@@ -0,0 +1,94 @@
+package dnsapi
+
+import "strings"
+
+// PlanRecordSet 返回将同名同类型的记录 existing 修改为只包含 desired 中的记录值所需的修改
+//
+// 记录值相同（MX 记录还需要优先级相同）的记录保留，多余的记录优先修改为缺少的记录值，
+// 剩余的再删除，仍然缺少的记录值使用 desired 中的参数创建。
+// 保留和修改的记录以原记录为初始值，经过 apply 处理后与原记录不同时更新，apply 可以为 nil。
+// 返回的修改按更新、创建、删除排序，记录已经一致时返回 nil。
+func PlanRecordSet(existing []Record, desired []*Parameter, apply func(p *Parameter)) []Change {
+	var unique, missing []*Parameter
+	for _, p := range desired {
+		if !containsValue(unique, p) {
+			unique = append(unique, p)
+		}
+	}
+	kept := make(map[int]bool)
+	for _, p := range unique {
+		if i := findRecordValue(existing, kept, p); i >= 0 {
+			kept[i] = true
+			continue
+		}
+		missing = append(missing, p)
+	}
+
+	var updates, creates, deletes []Change
+	for i := range existing {
+		r := &existing[i]
+		p := r.Parameter()
+		if !kept[i] {
+			if len(missing) == 0 {
+				deletes = append(deletes, Change{Action: ChangeDelete, Param: p})
+				continue
+			}
+			p.Value, p.Priority = missing[0].Value, missing[0].Priority
+			p.SRV, p.CAA, p.MX = missing[0].SRV, missing[0].CAA, missing[0].MX
+			missing = missing[1:]
+		}
+		if apply != nil {
+			apply(p)
+		}
+		if !kept[i] || p.TTL != r.TTL || p.Weight != r.Weight || p.Proxied != r.Proxied || p.Remark != r.Remark {
+			updates = append(updates, Change{Action: ChangeUpdate, Param: p})
+		}
+	}
+	for _, p := range missing {
+		creates = append(creates, Change{Action: ChangeCreate, Param: p})
+	}
+
+	changes := append(append(updates, creates...), deletes...)
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// findRecordValue 返回 records 中未保留且记录值与 p 相同的记录下标，没有时返回 -1
+func findRecordValue(records []Record, kept map[int]bool, p *Parameter) int {
+	for i, r := range records {
+		if !kept[i] && sameValue(r.Type, r.Value, r.Priority, p) {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsValue 判断 params 中是否已经有与 p 记录值相同的参数
+func containsValue(params []*Parameter, p *Parameter) bool {
+	for _, q := range params {
+		if sameValue(q.Type, q.Value, q.Priority, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue 判断记录值是否相同，MX 记录的优先级不在记录值中，需要单独比较
+func sameValue(typ, value string, priority int, p *Parameter) bool {
+	if normalizeValue(typ, value) != normalizeValue(p.Type, p.Value) {
+		return false
+	}
+	return typ != "MX" || priority == p.Priority
+}
+
+// normalizeValue 返回用于比较的记录值，记录值为域名的记录去掉末尾的点并转为小写，
+// 服务商返回的域名可能是带点的完整域名，大小写也可能与指定的不同
+func normalizeValue(typ, value string) string {
+	switch strings.ToUpper(typ) {
+	case "CNAME", "MX", "NS", "SRV", "PTR":
+		return strings.ToLower(strings.TrimSuffix(value, "."))
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package dnsapi
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPlanRecordSet(t *testing.T) {
+	existing := []Record{
+		{ID: "1", Domain: "example.com", Name: "www", Type: "A", Value: "1.1.1.1", TTL: 600},
+		{ID: "2", Domain: "example.com", Name: "www", Type: "A", Value: "2.2.2.2", TTL: 600},
+		{ID: "3", Domain: "example.com", Name: "www", Type: "A", Value: "3.3.3.3", TTL: 600},
+	}
+	values := func(vs ...string) []*Parameter {
+		params := make([]*Parameter, 0, len(vs))
+		for _, v := range vs {
+			params = append(params, &Parameter{Domain: "example.com", Name: "www", Type: "A", Value: v})
+		}
+		return params
+	}
+	plan := func(changes []Change) []string {
+		s := make([]string, 0, len(changes))
+		for _, c := range changes {
+			s = append(s, c.String())
+		}
+		return s
+	}
+	ttl := func(p *Parameter) { p.TTL = 1200 }
+
+	tests := []struct {
+		name    string
+		desired []*Parameter
+		apply   func(p *Parameter)
+		want    []string
+	}{
+		{"不变", values("3.3.3.3", "1.1.1.1", "2.2.2.2"), nil, nil},
+		{"删除多余", values("1.1.1.1"), nil, []string{"delete 2", "delete 3"}},
+		{"修改多余", values("1.1.1.1", "4.4.4.4"), nil, []string{"update 2 www A 4.4.4.4", "delete 3"}},
+		{"创建缺少", values("1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4", "4.4.4.4"), nil, []string{"create www A 4.4.4.4"}},
+		{"重复的已有记录值", values("1.1.1.1", "1.1.1.1", "2.2.2.2", "3.3.3.3"), nil, nil},
+		{"修改属性", values("1.1.1.1", "2.2.2.2", "3.3.3.3"), ttl, []string{"update 1 www A 1.1.1.1", "update 2 www A 2.2.2.2", "update 3 www A 3.3.3.3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plan(PlanRecordSet(existing, tt.desired, tt.apply)); !slices.Equal(got, tt.want) {
+				t.Errorf("修改 = %v, 应为 %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := PlanRecordSet(nil, values("1.1.1.1"), ttl); len(got) != 1 || got[0].Param.TTL != 0 {
+		t.Errorf("创建的记录不应经过 apply 处理: %v", got)
+	}
+	mx := []Record{{ID: "1", Name: "@", Type: "MX", Value: "mx.example.com", Priority: 10}}
+	want := []string{"update 1 @ MX mx.example.com"}
+	got := plan(PlanRecordSet(mx, []*Parameter{{Name: "@", Type: "MX", Value: "mx.example.com", Priority: 20}}, nil))
+	if !slices.Equal(got, want) {
+		t.Errorf("MX 记录修改优先级 = %v, 应为 %v", got, want)
+	}
+
+	// 服务商返回的域名带末尾的点且大小写不同，再次执行时不应修改
+	cname := []Record{{ID: "1", Name: "www", Type: "CNAME", Value: "Target.Example.com."}}
+	desired := []*Parameter{{Name: "www", Type: "CNAME", Value: "target.example.com"}}
+	if got := plan(PlanRecordSet(cname, desired, nil)); len(got) != 0 {
+		t.Errorf("CNAME 记录值相同时修改 = %v, 应为空", got)
+	}
+	mxExisting := []Record{{ID: "1", Name: "@", Type: "MX", Value: "mx2.example.com.", Priority: 20}}
+	mxDesired := []*Parameter{{Name: "@", Type: "MX", Value: "mx1.example.com", Priority: 10}, {Name: "@", Type: "MX", Value: "mx2.example.com", Priority: 20}}
+	changes := PlanRecordSet(mxExisting, mxDesired, nil)
+	for _, c := range changes {
+		mxExisting = append(mxExisting, Record{ID: "2", Name: c.Param.Name, Type: c.Param.Type, Value: c.Param.Value + ".", Priority: c.Param.Priority})
+	}
+	if got := plan(PlanRecordSet(mxExisting, mxDesired, nil)); len(got) != 0 {
+		t.Errorf("第二次执行的修改 = %v, 应为空", got)
+	}
+}
@@ -0,0 +1,144 @@
+package dnsapi
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CredentialField 描述服务商需要的一项认证信息
+type CredentialField struct {
+	// Key 配置文件中 credentials 下的键名，对应的命令行参数为把下划线替换为中划线后的 --key
+	Key string
+	// Description 说明信息，用于交互输入提示和命令行参数帮助
+	Description string
+	// Secret 是否为敏感信息，交互输入时不回显
+	Secret bool
+	// Required 是否必填
+	Required bool
+	// Validate 校验输入值，为 nil 时不校验；非必填字段为空时不会调用
+	Validate func(string) error
+}
+
+// Config 创建服务商客户端所需的配置
+type Config struct {
+	// Credentials 认证信息，键名与 CredentialField.Key 对应
+	Credentials map[string]string
+	// Endpoint API 访问地址，可以是主机名或完整的 URL，为空时使用服务商的默认地址
+	Endpoint string
+	// Region 地域，为空时使用服务商的默认地域，不区分地域的服务商会忽略此配置
+	Region string
+}
+
+// Get 返回指定键名的认证信息
+func (c Config) Get(key string) string {
+	return c.Credentials[key]
+}
+
+// Provider 服务商注册信息
+type Provider struct {
+	// Name 服务商名称，即配置文件中的 type
+	Name string
+	// Description 服务商说明
+	Description string
+	// Credentials 需要的认证信息
+	Credentials []CredentialField
+	// Validate 校验完整的认证信息，用于字段之间存在依赖的情况，可以为 nil
+	Validate func(credentials map[string]string) error
+	// New 创建服务商客户端
+	New func(cfg Config) (DNSAPI, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]*Provider)
+)
+
+// Register 注册服务商，通常在服务商包的 init 函数中调用，重复注册会 panic
+func Register(p *Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if p == nil || p.New == nil {
+		panic("dnsapi: Register provider is nil")
+	}
+	if _, dup := providers[p.Name]; dup {
+		panic("dnsapi: Register called twice for provider " + p.Name)
+	}
+	providers[p.Name] = p
+}
+
+// LookupProvider 根据名称查找已注册的服务商
+func LookupProvider(name string) (*Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[name]
+	if !ok {
+		return nil, Errorf(ErrInvalidInput, "不支持的 DNS 服务提供商: %s", name)
+	}
+	return p, nil
+}
+
+// Providers 返回所有已注册的服务商，按名称排序
+func Providers() []*Provider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	list := make([]*Provider, 0, len(providers))
+	for _, p := range providers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// ProviderNames 返回所有已注册的服务商名称，按名称排序
+func ProviderNames() []string {
+	list := Providers()
+	names := make([]string, 0, len(list))
+	for _, p := range list {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// New 创建指定服务商的客户端
+func New(name string, cfg Config) (DNSAPI, error) {
+	p, err := LookupProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ValidCredentials(cfg.Credentials); err != nil {
+		return nil, err
+	}
+	return p.New(cfg)
+}
+
+// ValidCredentials 校验认证信息是否完整
+func (p *Provider) ValidCredentials(credentials map[string]string) error {
+	for _, f := range p.Credentials {
+		v := credentials[f.Key]
+		if v == "" {
+			if f.Required {
+				return Errorf(ErrInvalidInput, "%s 缺少认证信息: %s", p.Name, f.Key)
+			}
+			continue
+		}
+		if f.Validate != nil {
+			if err := f.Validate(v); err != nil {
+				return Errorf(ErrInvalidInput, "%s: %v", f.Key, err)
+			}
+		}
+	}
+	if p.Validate != nil {
+		return p.Validate(credentials)
+	}
+	return nil
+}
+
+func (p *Provider) String() string {
+	if p.Description == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, p.Description)
+}
@@ -0,0 +1,105 @@
+package dnsapi
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultRetries 默认的最大重试次数
+	DefaultRetries = 3
+	// DefaultRetryBaseDelay 默认的第一次重试等待时间
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// DefaultRetryMaxDelay 默认的单次重试最长等待时间
+	DefaultRetryMaxDelay = 30 * time.Second
+)
+
+// RetryConfig 自动重试的配置
+type RetryConfig struct {
+	// Retries 失败后的最大重试次数，0 表示不重试
+	Retries int
+	// BaseDelay 第一次重试前的等待时间，之后每次翻倍，为 0 时使用 DefaultRetryBaseDelay
+	BaseDelay time.Duration
+	// MaxDelay 单次等待的最长时间，为 0 时使用 DefaultRetryMaxDelay，服务商要求的 Retry-After 不受此限制
+	MaxDelay time.Duration
+	// OnRetry 每次重试前调用，可以为 nil
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// Retry 返回遇到限流、服务端错误和网络错误时自动重试的中间件
+//
+// 查询、修改和删除按幂等操作处理，遇到上述错误都会重试；添加记录、添加域名和批量修改
+// 只在被限流时重试，因为服务端错误和网络错误时无法确定请求是否已经执行。
+// cfg.Retries 不大于 0 时不包装。
+func Retry(cfg RetryConfig) Middleware {
+	if cfg.Retries <= 0 {
+		return func(api DNSAPI) DNSAPI { return api }
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultRetryBaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultRetryMaxDelay
+	}
+	return Intercept(func(ctx context.Context, call *Call, invoke func(ctx context.Context) error) error {
+		for attempt := 0; ; attempt++ {
+			err := invoke(ctx)
+			if attempt >= cfg.Retries || !IsRetryable(err, call.Idempotent()) {
+				return err
+			}
+			d := cfg.delay(attempt, err)
+			if cfg.OnRetry != nil {
+				cfg.OnRetry(attempt+1, d, err)
+			}
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	})
+}
+
+// IsRetryable 判断失败的请求是否可以重试
+//
+// 被限流的请求服务商没有执行，总是可以重试；服务端错误和网络错误只有幂等的请求可以重试。
+func IsRetryable(err error, idempotent bool) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// delay 返回第 attempt 次重试前的等待时间
+//
+// 服务商指定了 Retry-After 时按其等待，否则使用指数退避，并在 [d/2, d) 之间随机，
+// 避免并发的请求同时重试。
+func (cfg RetryConfig) delay(attempt int, err error) time.Duration {
+	var e *Error
+	if errors.As(err, &e) && e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	d := cfg.MaxDelay
+	if attempt < 30 {
+		d = min(cfg.BaseDelay<<attempt, cfg.MaxDelay)
+	}
+	if d < 2 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
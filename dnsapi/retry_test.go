@@ -0,0 +1,140 @@
+package dnsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// flaky 依次返回 errs 中的错误，之后的调用成功
+type flaky struct {
+	DNSAPI
+	errs  []error
+	calls int
+}
+
+func (f *flaky) next() error {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return f.errs[f.calls-1]
+	}
+	return nil
+}
+
+func (f *flaky) ListDomains(ctx context.Context) ([]Domain, error) {
+	return nil, f.next()
+}
+
+func (f *flaky) AddRecord(ctx context.Context, param *Parameter) error {
+	return f.next()
+}
+
+// flakyBatcher 实现了 Batcher 的 flaky
+type flakyBatcher struct {
+	*flaky
+}
+
+func (f flakyBatcher) ApplyChanges(ctx context.Context, domain string, changes []Change) ([]ChangeResult, error) {
+	return nil, f.next()
+}
+
+func TestRetry(t *testing.T) {
+	rateLimited := NewError(ErrRateLimited, "Throttling", "", nil)
+	unavailable := NewError(ErrUnavailable, "InternalError", "", nil)
+	cfg := RetryConfig{Retries: 2, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name  string
+		call  func(api DNSAPI) error
+		errs  []error
+		calls int
+		err   error
+	}{
+		{"查询限流后成功", listDomains, []error{rateLimited, rateLimited}, 3, nil},
+		{"查询服务端错误后成功", listDomains, []error{unavailable}, 2, nil},
+		{"查询超过重试次数", listDomains, []error{rateLimited, rateLimited, rateLimited}, 3, ErrRateLimited},
+		{"查询不重试其他错误", listDomains, []error{Errorf(ErrNotFound, "")}, 1, ErrNotFound},
+		{"网络错误", listDomains, []error{&timeoutError{}}, 2, nil},
+		{"添加记录限流后成功", addRecord, []error{rateLimited}, 2, nil},
+		{"添加记录不重试服务端错误", addRecord, []error{unavailable}, 1, ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &flaky{errs: tt.errs}
+			err := tt.call(Retry(cfg)(f))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("错误 = %v, 应为 %v", err, tt.err)
+			}
+			if f.calls != tt.calls {
+				t.Errorf("调用次数 = %d, 应为 %d", f.calls, tt.calls)
+			}
+		})
+	}
+}
+
+func listDomains(api DNSAPI) error {
+	_, err := api.ListDomains(context.Background())
+	return err
+}
+
+func addRecord(api DNSAPI) error {
+	return api.AddRecord(context.Background(), &Parameter{})
+}
+
+// timeoutError 模拟网络超时
+type timeoutError struct{}
+
+func (*timeoutError) Error() string   { return "i/o timeout" }
+func (*timeoutError) Timeout() bool   { return true }
+func (*timeoutError) Temporary() bool { return true }
+
+func TestRetryAfter(t *testing.T) {
+	err := NewError(ErrRateLimited, "971", "", nil)
+	err.RetryAfter = 50 * time.Millisecond
+	var delays []time.Duration
+	api := Retry(RetryConfig{
+		Retries:   1,
+		BaseDelay: time.Millisecond,
+		MaxDelay:  time.Millisecond,
+		OnRetry:   func(_ int, d time.Duration, _ error) { delays = append(delays, d) },
+	})(&flaky{errs: []error{err}})
+	if err := listDomains(api); err != nil {
+		t.Fatal(err)
+	}
+	if len(delays) != 1 || delays[0] != 50*time.Millisecond {
+		t.Errorf("等待时间 = %v, 应为 [50ms]", delays)
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &flaky{errs: []error{Errorf(ErrRateLimited, ""), Errorf(ErrRateLimited, "")}}
+	api := Retry(RetryConfig{
+		Retries:   5,
+		BaseDelay: time.Hour,
+		OnRetry:   func(int, time.Duration, error) { cancel() },
+	})(f)
+	if _, err := api.ListDomains(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("错误 = %v, 应为 ErrRateLimited", err)
+	}
+	if f.calls != 1 {
+		t.Errorf("调用次数 = %d, 应为 1", f.calls)
+	}
+}
+
+func TestRetryBatcher(t *testing.T) {
+	if _, ok := Retry(RetryConfig{Retries: 1})(&flaky{}).(Batcher); ok {
+		t.Error("内层客户端没有实现 Batcher 时不应实现 Batcher")
+	}
+	f := flakyBatcher{&flaky{errs: []error{fmt.Errorf("提交失败: %w", Errorf(ErrRateLimited, ""))}}}
+	api := Retry(RetryConfig{Retries: 1, BaseDelay: time.Millisecond})(f)
+	b, ok := api.(Batcher)
+	if !ok {
+		t.Fatal("内层客户端实现了 Batcher 时应实现 Batcher")
+	}
+	if _, err := b.ApplyChanges(context.Background(), "example.com", nil); err != nil {
+		t.Errorf("重试后 ApplyChanges 失败: %v", err)
+	}
+}
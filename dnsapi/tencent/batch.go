@@ -0,0 +1,198 @@
+package tencent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	dnspod "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/dnspod/v20210323"
+)
+
+// batchPollInterval 查询批量任务执行结果的间隔
+var batchPollInterval = time.Second
+
+// ApplyChanges 批量修改解析记录
+//
+// 删除和添加使用 DNSPod 的批量接口，批量修改接口只能把多条记录的同一个字段改为相同的值，
+// 因此更新仍然逐条执行。批量接口是异步任务，这里会等待任务执行完成后返回每条记录的结果。
+// https://cloud.tencent.com/document/api/1427/80777
+func (p *Client) ApplyChanges(ctx context.Context, domain string, changes []dnsapi.Change) ([]dnsapi.ChangeResult, error) {
+	results := make([]dnsapi.ChangeResult, len(changes))
+	var deletes, creates []int
+	for i, c := range changes {
+		results[i].Change = c
+		switch c.Action {
+		case dnsapi.ChangeDelete:
+			deletes = append(deletes, i)
+		case dnsapi.ChangeCreate:
+			creates = append(creates, i)
+		}
+	}
+
+	// 执行顺序与 Cloudflare 一致：删除、更新、添加
+	if len(deletes) > 0 {
+		p.deleteRecordBatch(ctx, results, deletes)
+	}
+	for i, c := range changes {
+		if c.Action != dnsapi.ChangeDelete && c.Action != dnsapi.ChangeCreate {
+			results[i].Err = dnsapi.ApplyChange(ctx, p, domain, c)
+		}
+	}
+	if len(creates) > 0 {
+		p.createRecordBatch(ctx, domain, results, creates)
+	}
+	return results, nil
+}
+
+// deleteRecordBatch 批量删除 results 中下标为 indexes 的记录，并设置每条记录的执行结果
+func (p *Client) deleteRecordBatch(ctx context.Context, results []dnsapi.ChangeResult, indexes []int) {
+	request := dnspod.NewDeleteRecordBatchRequest()
+	pending := make([]int, 0, len(indexes))
+	for _, i := range indexes {
+		id, err := strconv.ParseUint(results[i].Change.Param.ID, 10, 64)
+		if err != nil {
+			results[i].Err = dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的记录ID: %v", err)
+			continue
+		}
+		request.RecordIdList = append(request.RecordIdList, common.Uint64Ptr(id))
+		pending = append(pending, i)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	response, err := p.client.DeleteRecordBatchWithContext(ctx, request)
+	if err != nil {
+		setErr(results, pending, fmt.Errorf("批量删除解析记录失败: %w", wrapError(ctx, err)))
+		return
+	}
+	records, err := p.waitBatchTask(ctx, uint64Value(response.Response.JobId))
+	if err != nil {
+		setErr(results, pending, err)
+		return
+	}
+
+	byID := make(map[string]*dnspod.BatchRecordInfo, len(records))
+	for _, r := range records {
+		byID[strconv.FormatUint(uint64Value(r.RecordId), 10)] = r
+	}
+	for _, i := range pending {
+		results[i].Err = batchRecordErr(byID[results[i].Change.Param.ID])
+	}
+}
+
+// createRecordBatch 批量添加 results 中下标为 indexes 的记录，并设置每条记录的执行结果
+func (p *Client) createRecordBatch(ctx context.Context, domain string, results []dnsapi.ChangeResult, indexes []int) {
+	info, err := p.describeDomain(ctx, domain)
+	if err != nil {
+		setErr(results, indexes, err)
+		return
+	}
+
+	request := dnspod.NewCreateRecordBatchRequest()
+	request.DomainIdList = []*string{common.StringPtr(strconv.FormatUint(uint64Value(info.DomainId), 10))}
+	pending := make([]int, 0, len(indexes))
+	for _, i := range indexes {
+		param := *results[i].Change.Param
+		if err := param.ParseData(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		record := &dnspod.AddRecordBatch{
+			SubDomain:  common.StringPtr(param.Name),
+			RecordType: common.StringPtr(param.Type),
+			Value:      common.StringPtr(param.Value),
+		}
+		if param.Line != "" {
+			record.RecordLine = common.StringPtr(param.Line)
+		}
+		if param.TTL > 0 {
+			record.TTL = common.Uint64Ptr(uint64(param.TTL))
+		}
+		if param.Type == "MX" {
+			record.MX = common.Uint64Ptr(uint64(param.Priority))
+		}
+		if param.Weight > 0 || param.ZeroWeight {
+			record.Weight = common.Uint64Ptr(uint64(param.Weight))
+		}
+		if param.Remark != "" {
+			record.Remark = common.StringPtr(param.Remark)
+		}
+		request.RecordList = append(request.RecordList, record)
+		pending = append(pending, i)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	response, err := p.client.CreateRecordBatchWithContext(ctx, request)
+	if err != nil {
+		setErr(results, pending, fmt.Errorf("批量添加解析记录失败: %w", wrapError(ctx, err)))
+		return
+	}
+	records, err := p.waitBatchTask(ctx, uint64Value(response.Response.JobId))
+	if err != nil {
+		setErr(results, pending, err)
+		return
+	}
+
+	// 只有一个域名，任务结果中记录的顺序与请求中的顺序一致
+	for n, i := range pending {
+		var record *dnspod.BatchRecordInfo
+		if n < len(records) {
+			record = records[n]
+		}
+		results[i].Err = batchRecordErr(record)
+	}
+}
+
+// waitBatchTask 等待批量任务执行完成，返回任务中每条记录的执行结果
+func (p *Client) waitBatchTask(ctx context.Context, jobID uint64) ([]*dnspod.BatchRecordInfo, error) {
+	request := dnspod.NewDescribeBatchTaskRequest()
+	request.JobId = common.Uint64Ptr(jobID)
+	for {
+		response, err := p.client.DescribeBatchTaskWithContext(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("获取批量任务结果失败: %w", wrapError(ctx, err))
+		}
+		// 任务开始执行前可能还没有 TotalCount
+		r := response.Response
+		if r.TotalCount != nil && uint64Value(r.SuccessCount)+uint64Value(r.FailCount) >= *r.TotalCount {
+			var records []*dnspod.BatchRecordInfo
+			for _, d := range r.DetailList {
+				records = append(records, d.RecordList...)
+			}
+			return records, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(batchPollInterval):
+		}
+	}
+}
+
+// batchRecordErr 返回批量任务中一条记录的执行结果
+func batchRecordErr(record *dnspod.BatchRecordInfo) error {
+	if record == nil {
+		return errors.New("批量任务没有返回此记录的执行结果")
+	}
+	if stringValue(record.Status) == "success" {
+		return nil
+	}
+	if msg := stringValue(record.ErrMsg); msg != "" {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("执行失败，状态为 %s", stringValue(record.Status))
+}
+
+func setErr(results []dnsapi.ChangeResult, indexes []int, err error) {
+	for _, i := range indexes {
+		results[i].Err = err
+	}
+}
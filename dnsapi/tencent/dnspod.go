@@ -1,33 +1,82 @@
 package tencent
 
 import (
+	"context"
 	"fmt"
 	"github.com/liwanggui/dnscli-go/dnsapi"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
 	dnspod "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/dnspod/v20210323"
 	"strconv"
+	"strings"
 )
 
+func init() {
+	dnsapi.Register(&dnsapi.Provider{
+		Name:        "tencent",
+		Description: "腾讯云 DNSPod",
+		Credentials: []dnsapi.CredentialField{
+			{Key: "secret_id", Description: "腾讯云 API 密钥 SecretId", Secret: true, Required: true},
+			{Key: "secret_key", Description: "腾讯云 API 密钥 SecretKey", Secret: true, Required: true},
+		},
+		New: func(cfg dnsapi.Config) (dnsapi.DNSAPI, error) {
+			return NewClient(cfg.Get("secret_id"), cfg.Get("secret_key"), cfg.Region, cfg.Endpoint)
+		},
+	})
+}
+
+// defaultLine 默认解析线路，创建记录时必须指定线路
+const defaultLine = "默认"
+
+// defaultRegion 未配置地域时使用的地域，DNSPod 不区分地域，只影响请求的签名
+const defaultRegion = "ap-guangzhou"
+
 type Client struct {
 	client *dnspod.Client
 }
 
-func NewClient(secretID, secretKey string) (*Client, error) {
+// NewClient 创建 DNSPod 客户端
+//
+// region 为空时使用 ap-guangzhou，endpoint 为空时使用 dnspod.tencentcloudapi.com，
+// 国际站可以使用 dnspod.intl.tencentcloudapi.com。
+func NewClient(secretID, secretKey, region, endpoint string) (*Client, error) {
+	if region == "" {
+		region = defaultRegion
+	}
 	credential := common.NewCredential(secretID, secretKey)
 	cpf := profile.NewClientProfile()
-	client, err := dnspod.NewClient(credential, "ap-guangzhou", cpf)
+	client, err := dnspod.NewClient(credential, region, cpf)
 	if err != nil {
 		return nil, fmt.Errorf("创建DNSPod客户端失败: %v", err)
 	}
-	return &Client{client: client}, nil
+	c := &Client{client: client}
+	if endpoint != "" {
+		if err := c.SetEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// SetEndpoint 设置 API 的访问地址，默认使用腾讯云的公网地址
+func (p *Client) SetEndpoint(endpoint string) error {
+	scheme, host, err := dnsapi.ParseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Scheme = scheme
+	cpf.HttpProfile.Endpoint = host
+	p.client.WithProfile(cpf)
+	return nil
 }
 
-func (p *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error) {
+func (p *Client) ListRecords(ctx context.Context, param *dnsapi.Parameter) ([]dnsapi.Record, error) {
 	request := dnspod.NewDescribeRecordListRequest()
 	request.Domain = &param.Domain
 	request.Subdomain = &param.Name
 	request.RecordType = common.StringPtr(param.Type)
+	request.RecordLine = common.StringPtr(param.Line)
 	request.Keyword = common.StringPtr(param.Value)
 	request.Limit = common.Uint64Ptr(100)
 	records := make([]dnsapi.Record, 0, 20)
@@ -36,9 +85,12 @@ func (p *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error) {
 	// 循环获取所有数据
 	for {
 		request.Offset = common.Uint64Ptr(offset)
-		response, err := p.client.DescribeRecordList(request)
+		response, err := p.client.DescribeRecordListWithContext(ctx, request)
+		if isNoData(err) {
+			break
+		}
 		if err != nil {
-			return nil, fmt.Errorf("获取域名记录失败: %v", err)
+			return nil, fmt.Errorf("获取域名记录失败: %w", wrapError(ctx, err))
 		}
 
 		for _, r := range response.Response.RecordList {
@@ -48,7 +100,7 @@ func (p *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error) {
 				priority = int(*r.MX)
 			}
 
-			records = append(records, dnsapi.Record{
+			record := dnsapi.Record{
 				ID:       strconv.FormatUint(*r.RecordId, 10),
 				Domain:   param.Domain,
 				Name:     *r.Name,
@@ -57,8 +109,13 @@ func (p *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error) {
 				TTL:      ttl,
 				Line:     *r.Line,
 				Priority: priority,
+				Weight:   int(uint64Value(r.Weight)),
+				Status:   strings.ToUpper(stringValue(r.Status)),
+				Remark:   stringValue(r.Remark),
 				Updated:  *r.UpdatedOn,
-			})
+			}
+			record.SetData()
+			records = append(records, record)
 		}
 
 		offset += *response.Response.RecordCountInfo.ListCount
@@ -71,19 +128,19 @@ func (p *Client) ListRecords(param *dnsapi.Parameter) ([]dnsapi.Record, error) {
 }
 
 // GetRecord 获取特定记录的详情
-func (p *Client) GetRecord(param *dnsapi.Parameter) (*dnsapi.Record, error) {
+func (p *Client) GetRecord(ctx context.Context, param *dnsapi.Parameter) (*dnsapi.Record, error) {
 	id, err := strconv.ParseUint(param.ID, 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("无效的记录ID: %v", err)
+		return nil, dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的记录ID: %v", err)
 	}
 
 	request := dnspod.NewDescribeRecordRequest()
 	request.Domain = &param.Domain
 	request.RecordId = &id
 
-	response, err := p.client.DescribeRecord(request)
+	response, err := p.client.DescribeRecordWithContext(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("获取记录详情失败: %v", err)
+		return nil, fmt.Errorf("获取记录详情失败: %w", wrapError(ctx, err))
 	}
 
 	r := response.Response.RecordInfo
@@ -93,46 +150,90 @@ func (p *Client) GetRecord(param *dnsapi.Parameter) (*dnsapi.Record, error) {
 		priority = int(*r.MX)
 	}
 
-	return &dnsapi.Record{
+	record := &dnsapi.Record{
 		ID:       param.ID,
 		Domain:   param.Domain,
 		Name:     *r.SubDomain,
 		Type:     *r.RecordType,
 		Value:    *r.Value,
 		TTL:      ttl,
+		Line:     stringValue(r.RecordLine),
 		Priority: priority,
+		Weight:   int(uint64Value(r.Weight)),
+		Status:   recordStatus(r.Enabled),
+		Remark:   stringValue(r.Remark),
 		Updated:  *r.UpdatedOn,
-	}, nil
+	}
+	record.SetData()
+	return record, nil
+}
+
+// recordStatus 将记录详情中的 Enabled 转换为记录状态
+func recordStatus(enabled *uint64) string {
+	if uint64Value(enabled) == 0 {
+		return dnsapi.StatusDisable
+	}
+	return dnsapi.StatusEnable
 }
 
 // AddRecord 添加新的解析记录
-func (p *Client) AddRecord(param *dnsapi.Parameter) error {
+func (p *Client) AddRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	if err := param.ParseData(); err != nil {
+		return err
+	}
+	line := param.Line
+	if line == "" {
+		line = defaultLine
+	}
+
 	request := dnspod.NewCreateRecordRequest()
 	request.Domain = &param.Domain
 	request.SubDomain = &param.Name
 	request.RecordType = common.StringPtr(param.Type)
-	request.RecordLine = common.StringPtr("默认")
+	request.RecordLine = common.StringPtr(line)
 	request.Value = &param.Value
-	request.TTL = common.Uint64Ptr(uint64(param.TTL))
+	if param.TTL > 0 {
+		request.TTL = common.Uint64Ptr(uint64(param.TTL))
+	}
 
-	if param.Priority > 0 {
+	// DNSPod 的 SRV、CAA 记录值即为完整的记录值，MX 参数仅用于 MX 记录
+	if param.Type == "MX" {
 		mx := uint64(param.Priority)
 		request.MX = &mx
 	}
+	if param.Weight > 0 || param.ZeroWeight {
+		request.Weight = common.Uint64Ptr(uint64(param.Weight))
+	}
+	if param.Remark != "" {
+		request.Remark = common.StringPtr(param.Remark)
+	}
 
-	_, err := p.client.CreateRecord(request)
+	response, err := p.client.CreateRecordWithContext(ctx, request)
 	if err != nil {
-		return fmt.Errorf("添加解析记录失败: %v", err)
+		return fmt.Errorf("添加解析记录失败: %w", wrapError(ctx, err))
 	}
-
+	param.ID = strconv.FormatUint(*response.Response.RecordId, 10)
 	return nil
 }
 
 // UpdateRecord 更新现有解析记录
-func (p *Client) UpdateRecord(param *dnsapi.Parameter) error {
+func (p *Client) UpdateRecord(ctx context.Context, param *dnsapi.Parameter) error {
+	if err := param.ParseData(); err != nil {
+		return err
+	}
 	id, err := strconv.ParseUint(param.ID, 10, 64)
 	if err != nil {
-		return fmt.Errorf("无效的记录ID: %v", err)
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的记录ID: %v", err)
+	}
+
+	// 修改记录的接口必须指定线路，不指定时保留记录原来的线路
+	line := param.Line
+	if line == "" {
+		record, err := p.GetRecord(ctx, param)
+		if err != nil {
+			return err
+		}
+		line = record.Line
 	}
 
 	request := dnspod.NewModifyRecordRequest()
@@ -140,55 +241,205 @@ func (p *Client) UpdateRecord(param *dnsapi.Parameter) error {
 	request.RecordId = &id
 	request.SubDomain = &param.Name
 	request.RecordType = common.StringPtr(param.Type)
-	request.RecordLine = common.StringPtr("默认")
+	request.RecordLine = common.StringPtr(line)
 	request.Value = &param.Value
-	ttl := uint64(param.TTL)
-	request.TTL = &ttl
+	if param.TTL > 0 {
+		request.TTL = common.Uint64Ptr(uint64(param.TTL))
+	}
 
-	if param.Priority > 0 {
+	// DNSPod 的 SRV、CAA 记录值即为完整的记录值，MX 参数仅用于 MX 记录
+	if param.Type == "MX" {
 		mx := uint64(param.Priority)
 		request.MX = &mx
 	}
+	if param.Weight > 0 || param.ZeroWeight {
+		request.Weight = common.Uint64Ptr(uint64(param.Weight))
+	}
+	if param.Remark != "" {
+		request.Remark = common.StringPtr(param.Remark)
+	}
 
-	_, err = p.client.ModifyRecord(request)
+	_, err = p.client.ModifyRecordWithContext(ctx, request)
 	if err != nil {
-		return fmt.Errorf("更新解析记录失败: %v", err)
+		return fmt.Errorf("更新解析记录失败: %w", wrapError(ctx, err))
 	}
 
 	return nil
 }
 
 // DeleteRecord 删除解析记录
-func (p *Client) DeleteRecord(param *dnsapi.Parameter) error {
+func (p *Client) DeleteRecord(ctx context.Context, param *dnsapi.Parameter) error {
 	id, err := strconv.ParseUint(param.ID, 10, 64)
 	if err != nil {
-		return fmt.Errorf("无效的记录ID: %v", err)
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的记录ID: %v", err)
 	}
 
 	request := dnspod.NewDeleteRecordRequest()
 	request.Domain = &param.Domain
 	request.RecordId = &id
 
-	_, err = p.client.DeleteRecord(request)
+	_, err = p.client.DeleteRecordWithContext(ctx, request)
 	if err != nil {
-		return fmt.Errorf("删除解析记录失败: %v", err)
+		return fmt.Errorf("删除解析记录失败: %w", wrapError(ctx, err))
 	}
 
 	return nil
 }
 
+// SetRecordStatus 启用或暂停解析记录
+func (p *Client) SetRecordStatus(ctx context.Context, param *dnsapi.Parameter) error {
+	id, err := strconv.ParseUint(param.ID, 10, 64)
+	if err != nil {
+		return dnsapi.Errorf(dnsapi.ErrInvalidInput, "无效的记录ID: %v", err)
+	}
+	if err := dnsapi.ValidStatus(param.Status); err != nil {
+		return err
+	}
+
+	request := dnspod.NewModifyRecordStatusRequest()
+	request.Domain = &param.Domain
+	request.RecordId = &id
+	request.Status = common.StringPtr(param.Status)
+
+	_, err = p.client.ModifyRecordStatusWithContext(ctx, request)
+	if err != nil {
+		return fmt.Errorf("设置解析记录状态失败: %w", wrapError(ctx, err))
+	}
+	return nil
+}
+
+// ListLines 列出域名当前套餐支持的解析线路
+func (p *Client) ListLines(ctx context.Context, domain string) ([]dnsapi.Line, error) {
+	// 查询线路需要指定域名的套餐等级
+	info, err := p.describeDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	request := dnspod.NewDescribeRecordLineListRequest()
+	request.Domain = common.StringPtr(domain)
+	request.DomainGrade = info.Grade
+	response, err := p.client.DescribeRecordLineListWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("获取解析线路失败: %w", wrapError(ctx, err))
+	}
+
+	// DNSPod 的解析记录使用线路名称，线路代码与名称相同
+	lines := make([]dnsapi.Line, 0, len(response.Response.LineList))
+	for _, l := range response.Response.LineList {
+		name := stringValue(l.Name)
+		lines = append(lines, dnsapi.Line{Code: name, Name: name})
+	}
+	return lines, nil
+}
+
+// describeDomain 获取域名详情
+func (p *Client) describeDomain(ctx context.Context, domain string) (*dnspod.DomainInfo, error) {
+	request := dnspod.NewDescribeDomainRequest()
+	request.Domain = common.StringPtr(domain)
+	response, err := p.client.DescribeDomainWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("获取域名信息失败: %w", wrapError(ctx, err))
+	}
+	if response.Response.DomainInfo == nil {
+		return nil, dnsapi.Errorf(dnsapi.ErrNotFound, "域名不存在: %s", domain)
+	}
+	return response.Response.DomainInfo, nil
+}
+
 // ListDomains 列出账号下所有域名
-func (p *Client) ListDomains() ([]string, error) {
+func (p *Client) ListDomains(ctx context.Context) ([]dnsapi.Domain, error) {
 	request := dnspod.NewDescribeDomainListRequest()
-	response, err := p.client.DescribeDomainList(request)
+	request.Limit = common.Int64Ptr(100)
+	domains := make([]dnsapi.Domain, 0, 20)
+
+	var offset int64 = 0
+	for {
+		request.Offset = common.Int64Ptr(offset)
+		response, err := p.client.DescribeDomainListWithContext(ctx, request)
+		if isNoData(err) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("获取域名列表失败: %w", wrapError(ctx, err))
+		}
+
+		for _, d := range response.Response.DomainList {
+			domains = append(domains, dnsapi.Domain{
+				ID:          strconv.FormatUint(uint64Value(d.DomainId), 10),
+				Name:        stringValue(d.Name),
+				Status:      strings.ToLower(stringValue(d.Status)),
+				RecordCount: int(uint64Value(d.RecordCount)),
+				Plan:        stringValue(d.GradeTitle),
+				NameServers: common.StringValues(d.EffectiveDNS),
+				Created:     stringValue(d.CreatedOn),
+			})
+		}
+
+		offset += int64(len(response.Response.DomainList))
+		countInfo := response.Response.DomainCountInfo
+		if len(response.Response.DomainList) == 0 || countInfo == nil ||
+			uint64(offset) >= uint64Value(countInfo.DomainTotal) {
+			break
+		}
+	}
+	return domains, nil
+}
+
+// AddDomain 添加域名
+func (p *Client) AddDomain(ctx context.Context, domain string) (*dnsapi.Domain, error) {
+	request := dnspod.NewCreateDomainRequest()
+	request.Domain = common.StringPtr(domain)
+	response, err := p.client.CreateDomainWithContext(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("获取域名列表失败: %v", err)
+		return nil, fmt.Errorf("添加域名失败: %w", wrapError(ctx, err))
 	}
 
-	domains := make([]string, 0, len(response.Response.DomainList))
-	for _, d := range response.Response.DomainList {
-		domains = append(domains, *d.Name)
-		fmt.Println(*d.Name, *d.Status, *d.CreatedOn)
+	info := response.Response.DomainInfo
+	return &dnsapi.Domain{
+		ID:          strconv.FormatUint(uint64Value(info.Id), 10),
+		Name:        stringValue(info.Domain),
+		NameServers: common.StringValues(info.GradeNsList),
+	}, nil
+}
+
+// DeleteDomain 删除域名
+func (p *Client) DeleteDomain(ctx context.Context, domain string) error {
+	request := dnspod.NewDeleteDomainRequest()
+	request.Domain = common.StringPtr(domain)
+	_, err := p.client.DeleteDomainWithContext(ctx, request)
+	if err != nil {
+		return fmt.Errorf("删除域名失败: %w", wrapError(ctx, err))
 	}
-	return domains, nil
+	return nil
+}
+
+// Capabilities 返回 DNSPod 支持的功能
+func (p *Client) Capabilities() dnsapi.Capabilities {
+	return dnsapi.Capabilities{
+		Lines:       true,
+		Priority:    true,
+		Weight:      true,
+		MinWeight:   0,
+		MaxWeight:   100,
+		Remark:      true,
+		Status:      true,
+		Updated:     true,
+		MinTTL:      600,
+		RecordTypes: dnsapi.RecordTypes,
+	}
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func uint64Value(v *uint64) uint64 {
+	if v == nil {
+		return 0
+	}
+	return *v
 }
@@ -0,0 +1,29 @@
+package tencent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	"github.com/liwanggui/dnscli-go/dnsapi/dnsapitest"
+	"github.com/liwanggui/dnscli-go/dnsapi/fakeapi"
+)
+
+func TestConformance(t *testing.T) {
+	batchPollInterval = time.Millisecond
+	dnsapitest.Run(t, dnsapitest.Config{
+		Domain: "example.com",
+		New: func(t *testing.T) dnsapi.DNSAPI {
+			srv := fakeapi.NewTencent(t)
+			if _, err := srv.Backend.AddDomain(context.Background(), "example.com"); err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClient("id", "secret", "", srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return client
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package tencent
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	sdkerrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+)
+
+// wrapError 将腾讯云 SDK 返回的错误转换为 dnsapi.Error
+//
+// SDK 把 ctx 被取消或超时转换为 ClientError.NetworkError，这里检查 ctx 的状态还原为 ctx 的错误，
+// 使调用方可以用 errors.Is 判断。
+func wrapError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *sdkerrors.TencentCloudSDKError
+	if !errors.As(err, &e) {
+		return err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && e.GetCode() == "ClientError.NetworkError" {
+		return dnsapi.NewError(ctxErr, e.GetCode(), e.GetMessage(), err)
+	}
+	return dnsapi.NewError(classify(e.GetCode()), e.GetCode(), e.GetMessage(), err)
+}
+
+// classify 根据 DNSPod 错误码判断错误分类
+// https://cloud.tencent.com/document/api/1427/56192
+func classify(code string) error {
+	switch {
+	case code == "":
+		return nil
+	case strings.HasPrefix(code, "AuthFailure"),
+		strings.HasPrefix(code, "UnauthorizedOperation"):
+		return dnsapi.ErrAuthFailed
+	case strings.HasPrefix(code, "RequestLimitExceeded"):
+		return dnsapi.ErrRateLimited
+	case strings.HasPrefix(code, "InternalError"),
+		code == "ClientError.NetworkError":
+		return dnsapi.ErrUnavailable
+	case strings.HasPrefix(code, "ResourceNotFound"),
+		strings.Contains(code, "NotExist"),
+		strings.Contains(code, "NotFound"):
+		return dnsapi.ErrNotFound
+	case strings.HasPrefix(code, "ResourceInUse"),
+		strings.Contains(code, "Exist"),
+		strings.Contains(code, "Duplicate"),
+		strings.Contains(code, "Conflict"):
+		return dnsapi.ErrConflict
+	case strings.HasPrefix(code, "LimitExceeded"),
+		strings.HasPrefix(code, "ResourceInsufficient"),
+		strings.Contains(code, "Quota"):
+		return dnsapi.ErrQuotaExceeded
+	case strings.HasPrefix(code, "InvalidParameter"),
+		strings.HasPrefix(code, "MissingParameter"),
+		strings.HasPrefix(code, "UnknownParameter"):
+		return dnsapi.ErrInvalidInput
+	}
+	return nil
+}
+
+// isNoData 判断是否为查询结果为空的错误
+//
+// DNSPod 在列表查询没有数据时返回 ResourceNotFound.NoDataOf* 错误，而不是空列表。
+func isNoData(err error) bool {
+	var e *sdkerrors.TencentCloudSDKError
+	return errors.As(err, &e) && strings.HasPrefix(e.GetCode(), "ResourceNotFound.NoDataOf")
+}
@@ -0,0 +1,62 @@
+package tencent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/liwanggui/dnscli-go/dnsapi"
+	sdkerrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"AuthFailure.SignatureFailure", dnsapi.ErrAuthFailed},
+		{"UnauthorizedOperation", dnsapi.ErrAuthFailed},
+		{"RequestLimitExceeded", dnsapi.ErrRateLimited},
+		{"InternalError", dnsapi.ErrUnavailable},
+		{"ClientError.NetworkError", dnsapi.ErrUnavailable},
+		{"ResourceNotFound.NoDataOfRecord", dnsapi.ErrNotFound},
+		{"InvalidParameter.DomainNotExist", dnsapi.ErrNotFound},
+		{"InvalidParameter.RecordIdNotFound", dnsapi.ErrNotFound},
+		{"ResourceInUse", dnsapi.ErrConflict},
+		{"InvalidParameter.DomainExists", dnsapi.ErrConflict},
+		{"InvalidParameter.RecordDuplicate", dnsapi.ErrConflict},
+		{"LimitExceeded.RecordTtlLimit", dnsapi.ErrQuotaExceeded},
+		{"ResourceInsufficient", dnsapi.ErrQuotaExceeded},
+		{"InvalidParameter.SubdomainInvalid", dnsapi.ErrInvalidInput},
+		{"MissingParameter", dnsapi.ErrInvalidInput},
+		{"UnknownParameter", dnsapi.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			err := wrapError(context.Background(), sdkerrors.NewTencentCloudSDKError(tt.code, "message", "request"))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("wrapError(%s) = %v, 应为 %v", tt.code, err, tt.want)
+			}
+			var e *dnsapi.Error
+			if !errors.As(err, &e) || e.Code != tt.code || e.Message != "message" {
+				t.Errorf("wrapError(%s) = %#v, 应保留错误码和错误信息", tt.code, err)
+			}
+		})
+	}
+
+	if kind := classify("FailedOperation"); kind != nil {
+		t.Errorf("classify(FailedOperation) = %v, 应为 nil", kind)
+	}
+
+	// ctx 取消或超时后的网络错误还原为 ctx 的错误
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	network := sdkerrors.NewTencentCloudSDKError("ClientError.NetworkError", "Post ...: context canceled", "")
+	if err := wrapError(ctx, network); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx 取消后的网络错误 = %v, 应为 context.Canceled", err)
+	}
+	other := sdkerrors.NewTencentCloudSDKError("InvalidParameter", "message", "")
+	if err := wrapError(ctx, other); errors.Is(err, context.Canceled) || !errors.Is(err, dnsapi.ErrInvalidInput) {
+		t.Errorf("ctx 取消后的其他错误 = %v, 应保持原分类", err)
+	}
+}
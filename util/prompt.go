@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"syscall"
 
@@ -140,3 +141,13 @@ func ValidStringNotEmpty(s string) error {
 	}
 	return nil
 }
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// ValidEmail 判断邮箱地址是否合法
+func ValidEmail(email string) error {
+	if emailRegex.MatchString(email) {
+		return nil
+	}
+	return fmt.Errorf("无效的邮箱地址：%s", email)
+}
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"cmp"
+	"net/netip"
+	"strings"
+)
+
+// CompareNatural 按自然顺序比较字符串，其中的数字按数值比较，如 web2 排在 web10 之前，字母不区分大小写
+func CompareNatural(a, b string) int {
+	for a != "" && b != "" {
+		da, db := isDigit(a[0]), isDigit(b[0])
+		if da != db {
+			// 数字排在字母之前
+			if da {
+				return -1
+			}
+			return 1
+		}
+		var ca, cb string
+		ca, a = cutChunk(a, da)
+		cb, b = cutChunk(b, db)
+		var c int
+		if da {
+			c = compareDigits(ca, cb)
+		} else {
+			c = strings.Compare(strings.ToLower(ca), strings.ToLower(cb))
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// CompareAddr 比较记录值，IP 地址按地址大小排在前面，IPv4 在 IPv6 之前，其他记录值按自然顺序比较
+func CompareAddr(a, b string) int {
+	ipa, erra := netip.ParseAddr(a)
+	ipb, errb := netip.ParseAddr(b)
+	switch {
+	case erra == nil && errb == nil:
+		return ipa.Compare(ipb)
+	case erra == nil:
+		return -1
+	case errb == nil:
+		return 1
+	}
+	return CompareNatural(a, b)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// cutChunk 切分出 s 开头连续的数字或非数字部分
+func cutChunk(s string, digit bool) (chunk, rest string) {
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareDigits 按数值比较数字串，数值相同时前导零少的在前
+func compareDigits(a, b string) int {
+	ta, tb := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if c := cmp.Compare(len(ta), len(tb)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(ta, tb); c != 0 {
+		return c
+	}
+	return cmp.Compare(len(a), len(b))
+}
@@ -0,0 +1,70 @@
+package util
+
+import "testing"
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"web2", "web10", -1},
+		{"web10", "web10", 0},
+		{"WWW", "www", 0},
+		{"web", "web1", -1},
+		{"web2", "web02", -1},
+		{"007", "7", 1},
+		{"web007", "web8", -1},
+		{"1a", "a1", -1},
+		{"a10b", "a10a", 1},
+		{"v1.2.10", "v1.2.9", 1},
+		{"", "a", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := CompareNatural(tt.a, tt.b); sign(got) != tt.want {
+				t.Errorf("CompareNatural(%q, %q) = %d, 应为 %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := CompareNatural(tt.b, tt.a); sign(got) != -tt.want {
+				t.Errorf("CompareNatural(%q, %q) = %d, 应为 %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareAddr(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"10.0.0.2", "10.0.0.10", -1},
+		{"192.0.2.1", "192.0.2.1", 0},
+		{"192.0.2.1", "2001:db8::1", -1},
+		{"2001:db8::2", "2001:db8::10", -1},
+		{"255.255.255.255", "::1", -1},
+		{"1.1.1.1", "a.example.com", -1},
+		{"2001:db8::1", "a.example.com", -1},
+		{"1.1.1.1", "1.1.1", -1},
+		{"mx2.example.com", "mx10.example.com", -1},
+		{"10 mx.example.com", "5 mx.example.com", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := CompareAddr(tt.a, tt.b); sign(got) != tt.want {
+				t.Errorf("CompareAddr(%q, %q) = %d, 应为 %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := CompareAddr(tt.b, tt.a); sign(got) != -tt.want {
+				t.Errorf("CompareAddr(%q, %q) = %d, 应为 %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
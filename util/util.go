@@ -35,6 +35,9 @@ func GetStructFieldNamesAndValues(s interface{}, tagName string, exclField []str
 
 		// 获取 tag 名
 		fieldName := fieldType.Tag.Get(tagName)
+		if fieldName == "-" {
+			continue
+		}
 
 		if fieldName == "" {
 			fieldName = fieldType.Name
@@ -42,20 +45,54 @@ func GetStructFieldNamesAndValues(s interface{}, tagName string, exclField []str
 		fieldNameList = append(fieldNameList, strings.Split(fieldName, ",")[0])
 
 		// 获取字段值
-		switch field.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			fieldValueList = append(fieldValueList, strconv.FormatInt(field.Int(), 10))
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			fieldValueList = append(fieldValueList, strconv.FormatUint(field.Uint(), 10))
-		case reflect.Float32, reflect.Float64:
-			fieldValueList = append(fieldValueList, strconv.FormatFloat(field.Float(), 'g', -1, 64))
-		case reflect.String:
-			fieldValueList = append(fieldValueList, field.String())
-		case reflect.Bool:
-			fieldValueList = append(fieldValueList, fmt.Sprintf("%t", field.Bool()))
-		default:
-			fieldValueList = append(fieldValueList, fmt.Sprintf("%v", field.Interface()))
+		fieldValueList = append(fieldValueList, fieldValue(field))
+	}
+	return
+}
+
+// GetStructFieldsByName 按 fields 中字段名的顺序返回结构体字段的标签名和值，不存在的字段忽略
+func GetStructFieldsByName(s interface{}, tagName string, fields []string) (fieldNameList, fieldValueList []string) {
+	value := reflect.ValueOf(s)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	typeOf := value.Type()
+
+	for _, name := range fields {
+		fieldType, ok := typeOf.FieldByName(name)
+		if !ok {
+			continue
+		}
+		fieldName := strings.Split(fieldType.Tag.Get(tagName), ",")[0]
+		if fieldName == "" || fieldName == "-" {
+			fieldName = fieldType.Name
 		}
+		fieldNameList = append(fieldNameList, fieldName)
+		fieldValueList = append(fieldValueList, fieldValue(value.FieldByIndex(fieldType.Index)))
 	}
 	return
 }
+
+// fieldValue 返回字段值的字符串形式，切片以逗号连接
+func fieldValue(field reflect.Value) string {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, 64)
+	case reflect.String:
+		return field.String()
+	case reflect.Bool:
+		return fmt.Sprintf("%t", field.Bool())
+	case reflect.Slice:
+		items := make([]string, 0, field.Len())
+		for j := 0; j < field.Len(); j++ {
+			items = append(items, fmt.Sprintf("%v", field.Index(j).Interface()))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprintf("%v", field.Interface())
+	}
+}
@@ -0,0 +1,22 @@
+package util
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetStructFieldsByName(t *testing.T) {
+	type item struct {
+		ID    string   `table:"编号"`
+		Count int      `table:"-"`
+		Tags  []string `table:"标签"`
+		Note  string
+	}
+	v := &item{ID: "1", Count: 2, Tags: []string{"a", "b"}, Note: "x"}
+	names, values := GetStructFieldsByName(v, "table", []string{"Tags", "Missing", "Count", "Note", "ID"})
+	wantNames := []string{"标签", "Count", "Note", "编号"}
+	wantValues := []string{"a,b", "2", "x", "1"}
+	if !slices.Equal(names, wantNames) || !slices.Equal(values, wantValues) {
+		t.Errorf("GetStructFieldsByName = %v %v, 应为 %v %v", names, values, wantNames, wantValues)
+	}
+}